@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/razpinator/scaffo/internal/app"
 )
@@ -11,8 +15,15 @@ import (
 const Version = "0.0.5"
 
 func main() {
-	if len(os.Args) < 2 {
-		app.Execute()
+	// Ctrl-C cancels the running command, which removes its staging directory.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(os.Args) < 2 || isUIFlag(os.Args[1]) {
+		fs := flag.NewFlagSet("scaffo", flag.ExitOnError)
+		values := addValueFlags(fs)
+		mustParse(fs, os.Args[1:])
+		app.Execute(ctx, values.sources())
 		return
 	}
 
@@ -26,17 +37,67 @@ func main() {
 		fs.StringVar(&configPath, "config", "scaffold.config.json", "Path to config file")
 		fs.StringVar(&sourceRoot, "from", "", "Source project root")
 		mustParse(fs, args)
-		app.InitCommand(configPath, sourceRoot)
+		exitOnError(app.InitCommand(configPath, sourceRoot))
 	case "run":
-		var configPath, sourceRoot, outPath string
-		var copyConfig bool
+		var opts app.RunOptions
 		fs := flag.NewFlagSet("run", flag.ExitOnError)
-		fs.StringVar(&configPath, "config", "scaffold.config.json", "Path to config file")
-		fs.StringVar(&sourceRoot, "from", "", "Source project root (default: .)")
-		fs.StringVar(&outPath, "out", "", "Destination for generated project")
-		fs.BoolVar(&copyConfig, "copy-config", false, "Copy scaffold.config.json to the generated project")
+		fs.StringVar(&opts.ConfigPath, "config", "scaffold.config.json", "Path to config file")
+		fs.StringVar(&opts.SourceRoot, "from", "", "Source project root (default: .)")
+		fs.StringVar(&opts.OutPath, "out", "", "Destination for generated project")
+		fs.BoolVar(&opts.CopyConfig, "copy-config", false, "Copy scaffold.config.json to the generated project")
+		fs.BoolVar(&opts.DryRun, "dry-run", false, "Print the planned operations without writing anything")
+		fs.StringVar(&opts.PlanFormat, "output", "text", "Dry-run plan format: text or json")
+		fs.StringVar(&opts.DiffGlob, "diff", "", "Show unified diffs for templated files matching this glob (implies --dry-run)")
+		fs.IntVar(&opts.DiffLimit, "diff-limit", 0, "Show diffs for at most N files (implies --dry-run)")
+		fs.BoolVar(&opts.Strict, "strict", false, "Fail when tokens are left unresolved in the output")
+		fs.BoolVar(&opts.UseGitignore, "gitignore", false, "Also skip paths excluded by the source's .gitignore files")
+		fs.StringVar(&opts.Profile, "profile", "", "Apply a profile from the config")
+		addVerboseFlag(fs, &opts.Verbose)
+		values := addValueFlags(fs)
+		overwrite := addOverwriteFlags(fs)
+		mustParse(fs, args)
+		opts.Values = values.sources()
+		opts.Overwrite = overwrite.strategy()
+		_, err := app.Run(ctx, opts)
+		exitOnError(err)
+	case "build-template":
+		var opts app.BuildOptions
+		fs := flag.NewFlagSet("build-template", flag.ExitOnError)
+		fs.StringVar(&opts.ConfigPath, "config", "scaffold.config.json", "Path to config file")
+		fs.StringVar(&opts.SourceRoot, "from", "", "Source project root (default: sourceRoot from config)")
+		fs.StringVar(&opts.OutputDir, "output", "", "Destination for the template (default: templateRoot from config)")
+		fs.BoolVar(&opts.UseGitignore, "gitignore", false, "Also skip paths excluded by the source's .gitignore files")
+		addVerboseFlag(fs, &opts.Verbose)
 		mustParse(fs, args)
-		app.RunCommand(configPath, sourceRoot, outPath, copyConfig)
+		_, err := app.BuildTemplate(ctx, opts)
+		exitOnError(err)
+	case "generate":
+		var opts app.GenerateOptions
+		fs := flag.NewFlagSet("generate", flag.ExitOnError)
+		fs.StringVar(&opts.TemplateRoot, "template", "./template", "Template built with build-template")
+		fs.StringVar(&opts.OutPath, "out", "", "Destination for generated project")
+		fs.StringVar(&opts.ConfigPath, "config", "", "Optional config overriding the template's variables and hooks")
+		fs.BoolVar(&opts.CopyConfig, "copy-config", false, "Copy the template metadata (or --config) to the generated project")
+		fs.BoolVar(&opts.Strict, "strict", false, "Fail when tokens are left unresolved in the output")
+		values := addValueFlags(fs)
+		overwrite := addOverwriteFlags(fs)
+		mustParse(fs, args)
+		opts.Values = values.sources()
+		opts.Overwrite = overwrite.strategy()
+		_, err := app.Generate(ctx, opts)
+		exitOnError(err)
+	case "config":
+		if len(args) == 0 || args[0] != "show" {
+			fmt.Println("Usage: scaffo config show [--config <path>] [--resolved]")
+			os.Exit(2)
+		}
+		var configPath string
+		var resolved bool
+		fs := flag.NewFlagSet("config show", flag.ExitOnError)
+		fs.StringVar(&configPath, "config", "scaffold.config.json", "Path to config file")
+		fs.BoolVar(&resolved, "resolved", false, "Print the effective config, merged over the configs it extends")
+		mustParse(fs, args[1:])
+		exitOnError(app.ShowConfig(os.Stdout, configPath, resolved))
 	case "version", "--version", "-v":
 		fmt.Printf("scaffo version %s\n", Version)
 	default:
@@ -46,16 +107,122 @@ func main() {
 	}
 }
 
+// isUIFlag reports whether arg is a flag for the interactive UI rather than a
+// command (scaffo --values vars.yaml starts the UI with those values).
+func isUIFlag(arg string) bool {
+	return strings.HasPrefix(arg, "-") && arg != "--version" && arg != "-v"
+}
+
+// setFlag collects repeated --set NAME=value flags.
+type setFlag map[string]string
+
+func (s setFlag) String() string {
+	pairs := make([]string, 0, len(s))
+	for k, v := range s {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (s setFlag) Set(arg string) error {
+	name, value, err := app.ParseSetValue(arg)
+	if err != nil {
+		return err
+	}
+	s[name] = value
+	return nil
+}
+
+type valueFlags struct {
+	set            setFlag
+	valuesFile     string
+	nonInteractive bool
+}
+
+func addValueFlags(fs *flag.FlagSet) *valueFlags {
+	v := &valueFlags{set: setFlag{}}
+	fs.Var(v.set, "set", "Set a variable as NAME=value (repeatable)")
+	fs.StringVar(&v.valuesFile, "values", "", "YAML or JSON file with variable values")
+	fs.BoolVar(&v.nonInteractive, "non-interactive", false, "Never prompt; fail if a required variable has no value")
+	return v
+}
+
+func (v *valueFlags) sources() app.ValueSources {
+	return app.ValueSources{
+		Set:            v.set,
+		ValuesFile:     v.valuesFile,
+		NonInteractive: v.nonInteractive,
+	}
+}
+
+// addVerboseFlag registers -v and --verbose, which report why paths were skipped.
+func addVerboseFlag(fs *flag.FlagSet, verbose *bool) {
+	fs.BoolVar(verbose, "v", false, "Report which ignore rule excluded each skipped path")
+	fs.BoolVar(verbose, "verbose", false, "Report which ignore rule excluded each skipped path")
+}
+
+// overwriteFlags maps the mutually exclusive overwrite flags to a strategy.
+type overwriteFlags struct {
+	force, skipExisting, backup, interactive bool
+}
+
+func addOverwriteFlags(fs *flag.FlagSet) *overwriteFlags {
+	o := &overwriteFlags{}
+	fs.BoolVar(&o.force, "force", false, "Overwrite existing files in the output directory")
+	fs.BoolVar(&o.skipExisting, "skip-existing", false, "Keep existing files in the output directory")
+	fs.BoolVar(&o.backup, "backup", false, "Rename existing files to *.bak before writing")
+	fs.BoolVar(&o.interactive, "interactive", false, "Ask before replacing each existing file")
+	return o
+}
+
+func (o *overwriteFlags) strategy() string {
+	var selected []string
+	for _, f := range []struct {
+		set      bool
+		strategy string
+	}{
+		{o.force, app.OverwriteForce},
+		{o.skipExisting, app.OverwriteSkip},
+		{o.backup, app.OverwriteBackup},
+		{o.interactive, app.OverwriteInteractive},
+	} {
+		if f.set {
+			selected = append(selected, f.strategy)
+		}
+	}
+	if len(selected) > 1 {
+		fmt.Fprintf(os.Stderr, "Only one of --force, --skip-existing, --backup or --interactive may be set\n")
+		os.Exit(2)
+	}
+	if len(selected) == 0 {
+		return ""
+	}
+	return selected[0]
+}
+
 func mustParse(fs *flag.FlagSet, args []string) {
 	if err := fs.Parse(args); err != nil {
 		os.Exit(2)
 	}
 }
 
+// exitOnError reports a failed command on stderr and exits with status 1.
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
+
 func printUsage() {
 	fmt.Println("Usage: scaffo <command> [flags]")
 	fmt.Println("Commands:")
 	fmt.Println("  init --config <path> --from <source>")
-	fmt.Println("  run --config <path> --from <source> --out <dir>")
+	fmt.Println("  run --config <path> --from <source> --out <dir> [--set NAME=value] [--values <file>] [--non-interactive] [--dry-run [--output text|json]] [--diff <glob>] [--diff-limit N] [--strict] [--gitignore] [--profile <name>] [-v] [--force|--skip-existing|--backup|--interactive]")
+	fmt.Println("  build-template --config <path> --from <source> --output <dir> [--gitignore] [-v]")
+	fmt.Println("  generate --template <dir> --out <dir> [--set NAME=value] [--values <file>] [--non-interactive] [--strict] [--force|--skip-existing|--backup|--interactive]")
+	fmt.Println("  config show --config <path> [--resolved]")
 	fmt.Println("  version")
+	fmt.Println("Run without a command (optionally with --set/--values/--non-interactive) for the interactive UI.")
 }
@@ -1,47 +1,57 @@
 package app
 
 import (
-	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
 )
 
 // Execute runs the Bubble Tea menu and dispatches to the matching command.
-func Execute() {
+// values supplies variable values to every run started from the menu.
+// Cancelling ctx stops the current run, removing its staging directory, and
+// returns to the caller.
+func Execute(ctx context.Context, values ValueSources) {
 	for {
-		selected, arg, err := RunUI()
+		configPath := "scaffold.config.json"
+		selected, arg, profile, err := RunUI(configPath)
 		if err != nil {
 			fmt.Println("Error running Bubble Tea UI:", err)
 			os.Exit(1)
 		}
 
-		if selected == "quit" {
+		if selected == "quit" || ctx.Err() != nil {
 			fmt.Println("Goodbye!")
 			return
 		}
 
-		configPath := "scaffold.config.json"
 		sourceRoot := "./"
 
 		switch selected {
 		case "init":
-			InitCommand(configPath, sourceRoot)
-			pause()
+			if err := InitCommand(configPath, sourceRoot); err != nil {
+				fmt.Println("Error:", err)
+			}
+			pause(ctx)
 		case "run":
 			if arg != "" {
 				if arg == "Other (enter path)" {
 					fmt.Print("Enter source path: ")
-					reader := bufio.NewReader(os.Stdin)
-					text, _ := reader.ReadString('\n')
+					text, _ := readLine(ctx, stdinReader)
 					sourceRoot = strings.TrimSpace(text)
 				} else {
 					sourceRoot = arg
 				}
 			}
 			// RunCommand handles default outPath and prompting for variables
-			RunCommand(configPath, sourceRoot, "", false)
-			pause()
+			opts := RunOptions{ConfigPath: configPath, SourceRoot: sourceRoot, Profile: profile, Values: values}
+			if _, err := Run(ctx, opts); err != nil {
+				fmt.Println("Error:", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			pause(ctx)
 		default:
 			// Should not happen if RunUI returns valid commands or quit
 			fmt.Println("Goodbye!")
@@ -50,7 +60,7 @@ func Execute() {
 	}
 }
 
-func pause() {
+func pause(ctx context.Context) {
 	fmt.Println("\nPress Enter to return to menu...")
-	bufio.NewReader(os.Stdin).ReadBytes('\n')
+	readLine(ctx, stdinReader)
 }
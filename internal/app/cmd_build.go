@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BuildTemplateCommand applies the configured replacements and rename rules to the
+// source project and writes the resulting tokenized skeleton to outputDir.
+func BuildTemplateCommand(configPath, sourceRoot, outputDir string) error {
+	_, err := BuildTemplate(context.Background(), BuildOptions{
+		ConfigPath: configPath,
+		SourceRoot: sourceRoot,
+		OutputDir:  outputDir,
+	})
+	return err
+}
+
+// BuildTemplate writes a template skeleton as described by opts and reports what was written.
+func BuildTemplate(ctx context.Context, opts BuildOptions) (*Result, error) {
+	streams := opts.Streams.withDefaults()
+	configPath := resolveConfigPath(opts.ConfigPath)
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	if strings.TrimSpace(opts.SourceRoot) != "" {
+		cfg.SourceRoot = opts.SourceRoot
+	}
+	if opts.UseGitignore {
+		cfg.UseGitignore = true
+	}
+	sourceRoot, err := filepath.Abs(cfg.SourceRoot)
+	if err != nil {
+		return nil, fmt.Errorf("resolving source root: %w", err)
+	}
+
+	// Fall back to the configured template root, resolved relative to the config file
+	outputDir := opts.OutputDir
+	if strings.TrimSpace(outputDir) == "" {
+		outputDir = cfg.TemplateRoot
+		if strings.TrimSpace(outputDir) == "" {
+			outputDir = defaultTemplateOut
+		}
+		if !filepath.IsAbs(outputDir) {
+			outputDir = filepath.Join(filepath.Dir(configPath), outputDir)
+		}
+	}
+	outputDir, err = filepath.Abs(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving template path: %w", err)
+	}
+
+	if _, err := os.Stat(outputDir); err == nil {
+		return nil, fmt.Errorf("template path %s already exists", outputDir)
+	}
+
+	// Keep the config file out of the template if it lives inside the source tree
+	if absConfig, err := filepath.Abs(configPath); err == nil {
+		if rel, err := filepath.Rel(sourceRoot, absConfig); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			cfg.IgnoreFiles = mergePatterns(cfg.IgnoreFiles, []string{filepath.ToSlash(rel)})
+		}
+	}
+
+	fmt.Fprintf(streams.Out, "Building template from %s to %s...\n", sourceRoot, outputDir)
+
+	sortReplacementRules(cfg)
+
+	if err := runHooks(ctx, cfg, HookPreBuildTemplate, nil, outputDir, outputDir, sourceRoot, streams); err != nil {
+		return nil, err
+	}
+
+	stage, err := newStagingDir(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer stage.cleanup()
+
+	// No variable values: only replacements and rename rules are applied, so
+	// tokens are written to the template as-is.
+	res := &Result{OutPath: outputDir}
+	if err := scaffoldProject(ctx, cfg, sourceRoot, outputDir, stage.path, nil, res); err != nil {
+		return res, fmt.Errorf("building template: %w", err)
+	}
+	fmt.Fprintf(streams.Out, "Created %d templated file(s) and %d static asset(s)\n", len(res.FilesCreated), len(res.StaticCopied))
+	if opts.Verbose {
+		writeSkipReport(streams.Out, res.Skipped, res.SkippedBy)
+	}
+
+	meta := TemplateMetadata{
+		Name:             filepath.Base(sourceRoot),
+		CreatedAt:        time.Now().UTC(),
+		Token:            cfg.Token,
+		StaticFiles:      cfg.StaticFiles,
+		Variables:        cfg.Variables,
+		Hooks:            generateHooks(cfg.Hooks),
+		OutputName:       cfg.OutputName,
+		Engine:           cfg.Engine,
+		ConditionalPaths: cfg.ConditionalPaths,
+		TokenOverrides:   cfg.TokenOverrides,
+	}
+	if err := writeTemplateMetadata(stage.path, &meta); err != nil {
+		return res, fmt.Errorf("writing template metadata: %w", err)
+	}
+
+	if err := runHooks(ctx, cfg, HookPostBuildTemplate, nil, stage.path, outputDir, stage.path, streams); err != nil {
+		return res, err
+	}
+
+	if err := stage.commit(ctx, nil, res); err != nil {
+		return res, fmt.Errorf("moving template into place: %w", err)
+	}
+
+	fmt.Fprintf(streams.Out, "Template built at %s\n", outputDir)
+	return res, nil
+}
+
+func writeTemplateMetadata(templateRoot string, meta *TemplateMetadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(templateRoot, templateMetadataFile), data, 0o644)
+}
+
+func readTemplateMetadata(templateRoot string) (*TemplateMetadata, error) {
+	data, err := os.ReadFile(filepath.Join(templateRoot, templateMetadataFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s is not a scaffo template (missing %s)", templateRoot, templateMetadataFile)
+		}
+		return nil, err
+	}
+	var meta TemplateMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", templateMetadataFile, err)
+	}
+	return &meta, nil
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ShowConfig prints the config file at configPath. With resolved set it
+// prints the effective config instead: merged over the configs it extends
+// and with defaults applied, in the same format as the file.
+func ShowConfig(w io.Writer, configPath string, resolved bool) error {
+	configPath = resolveConfigPath(configPath)
+	if !resolved {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("reading config: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	data, err := cfg.encode(configPath)
+	if err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	_, err = w.Write(data)
+	return err
+}
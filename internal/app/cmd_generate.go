@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GenerateCommand creates a new project from a template skeleton produced by
+// BuildTemplateCommand. When configPath is set, its variables and hooks take
+// precedence over the ones recorded in the template metadata.
+func GenerateCommand(templateRoot, outPath string, copyConfig bool, configPath string) error {
+	_, err := Generate(context.Background(), GenerateOptions{
+		TemplateRoot: templateRoot,
+		OutPath:      outPath,
+		CopyConfig:   copyConfig,
+		ConfigPath:   configPath,
+	})
+	return err
+}
+
+// Generate creates a project from a template as described by opts and reports what was written.
+func Generate(ctx context.Context, opts GenerateOptions) (*Result, error) {
+	streams := opts.Streams.withDefaults()
+	if err := checkOverwriteStrategy(opts.Overwrite); err != nil {
+		return nil, err
+	}
+	templateRoot := opts.TemplateRoot
+	if strings.TrimSpace(templateRoot) == "" {
+		templateRoot = defaultTemplateOut
+	}
+
+	templateRoot, err := filepath.Abs(templateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("resolving template path: %w", err)
+	}
+
+	meta, err := readTemplateMetadata(templateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("loading template: %w", err)
+	}
+
+	// The skeleton is already filtered and tokenized, so only the metadata
+	// file itself is skipped and no replacements are applied.
+	cfg := &Config{
+		SourceRoot:       templateRoot,
+		Token:            meta.Token,
+		IgnoreFolders:    []string{},
+		IgnoreFiles:      []string{templateMetadataFile},
+		StaticFiles:      meta.StaticFiles,
+		Variables:        meta.Variables,
+		Hooks:            meta.Hooks,
+		OutputName:       meta.OutputName,
+		Engine:           meta.Engine,
+		ConditionalPaths: meta.ConditionalPaths,
+		TokenOverrides:   meta.TokenOverrides,
+	}
+	if err := checkEngine(cfg.Engine); err != nil {
+		return nil, fmt.Errorf("loading template: %w", err)
+	}
+	if err := checkTokenOverrides(cfg.TokenOverrides); err != nil {
+		return nil, fmt.Errorf("loading template: %w", err)
+	}
+	if err := checkStrict(cfg, opts.Strict); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(opts.ConfigPath) != "" {
+		override, err := LoadConfig(opts.ConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading config: %w", err)
+		}
+		if len(override.Variables) > 0 {
+			cfg.Variables = override.Variables
+		}
+		if len(override.Hooks) > 0 {
+			cfg.Hooks = override.Hooks
+		}
+		if override.OutputName != "" {
+			cfg.OutputName = override.OutputName
+		}
+		if len(override.ConditionalPaths) > 0 {
+			cfg.ConditionalPaths = override.ConditionalPaths
+		}
+		if len(override.TokenOverrides) > 0 {
+			cfg.TokenOverrides = override.TokenOverrides
+		}
+	}
+	if cfg.Variables == nil {
+		cfg.Variables = map[string]Variable{}
+	}
+
+	values, err := collectVariableValues(ctx, cfg.Variables, opts.Values, streams)
+	if err != nil {
+		return nil, fmt.Errorf("collecting variable values: %w", err)
+	}
+
+	outPath := opts.OutPath
+	if strings.TrimSpace(outPath) == "" {
+		if outPath, err = defaultOutputPath(cfg, values); err != nil {
+			return nil, err
+		}
+	}
+
+	outPath, err = filepath.Abs(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolving output path: %w", err)
+	}
+
+	if _, err := os.Stat(outPath); err == nil && opts.Overwrite == "" {
+		return nil, fmt.Errorf("output path %s already exists (use --force, --skip-existing, --backup or --interactive)", outPath)
+	}
+
+	fmt.Fprintf(streams.Out, "Generating from template %s (%s) to %s...\n", templateRoot, meta.Name, outPath)
+
+	if err := runHooks(ctx, cfg, HookPreGenerate, values, outPath, outPath, templateRoot, streams); err != nil {
+		return nil, err
+	}
+
+	stage, err := newStagingDir(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer stage.cleanup()
+
+	res := &Result{OutPath: outPath}
+	if err := scaffoldProject(ctx, cfg, templateRoot, outPath, stage.path, values, res); err != nil {
+		return res, fmt.Errorf("generating project: %w", err)
+	}
+	fmt.Fprintf(streams.Out, "Created %d templated file(s) and %d static asset(s)\n", len(res.FilesCreated), len(res.StaticCopied))
+	if err := reportUnresolved(streams.ErrOut, res.Unresolved, opts.Strict); err != nil {
+		return res, err
+	}
+
+	if opts.CopyConfig {
+		src := filepath.Join(templateRoot, templateMetadataFile)
+		if strings.TrimSpace(opts.ConfigPath) != "" {
+			src = opts.ConfigPath
+		}
+		copyConfigFile(src, stage.path, outPath, streams)
+	}
+
+	if err := runHooks(ctx, cfg, HookPostGenerate, values, stage.path, outPath, stage.path, streams); err != nil {
+		return res, err
+	}
+
+	if err := stage.commit(ctx, newConflictPolicy(opts.Overwrite, streams), res); err != nil {
+		return res, fmt.Errorf("moving project into place: %w", err)
+	}
+	writeConflictSummary(streams.Out, res)
+
+	fmt.Fprintf(streams.Out, "Project generated at %s\n", outPath)
+	return res, nil
+}
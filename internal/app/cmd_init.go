@@ -9,7 +9,7 @@ import (
 )
 
 // InitCommand scans the source root, suggests ignore patterns, and writes a starter config.
-func InitCommand(configPath, sourceRoot string) {
+func InitCommand(configPath, sourceRoot string) error {
 	if strings.TrimSpace(sourceRoot) == "" {
 		sourceRoot = "."
 	}
@@ -29,8 +29,7 @@ func InitCommand(configPath, sourceRoot string) {
 	fmt.Printf("Initializing scaffold config using source root %s\n", sourceRoot)
 	entries, err := os.ReadDir(sourceRoot)
 	if err != nil {
-		fmt.Println("Error reading source root:", err)
-		return
+		return fmt.Errorf("reading source root: %w", err)
 	}
 	fmt.Printf("Discovered %d item(s) in %s\n", len(entries), sourceRoot)
 
@@ -48,9 +47,25 @@ func InitCommand(configPath, sourceRoot string) {
 		}
 	}
 
-	scaffoldIgnore := loadScaffoldIgnore(sourceRoot)
-	if len(scaffoldIgnore) > 0 {
-		fmt.Printf("Loaded %d pattern(s) from .scaffoldignore\n", len(scaffoldIgnore))
+	scaffoldIgnore, err := newIgnoreTree(sourceRoot, false)
+	if err != nil {
+		return err
+	}
+	if n := len(scaffoldIgnore.Rules()); n > 0 {
+		fmt.Printf("Loaded %d pattern(s) from .scaffoldignore\n", n)
+	}
+
+	start, end := defaultTokenDelims(nil)
+	if uses := findDelimiterUses(sourceRoot, start, end, scaffoldIgnore); len(uses) > 0 {
+		fmt.Printf("Warning: %d file(s) already contain the token delimiters %s %s:\n", len(uses), start, end)
+		for i, use := range uses {
+			if i == maxDelimiterWarnings {
+				fmt.Printf("  ... and %d more\n", len(uses)-i)
+				break
+			}
+			fmt.Printf("  %s\n", use)
+		}
+		fmt.Printf("Add tokenOverrides with other delimiters for them, or escape literal delimiters (%s%s or %sraw%s...%sendraw%s).\n", start, start, start, end, start, end)
 	}
 
 	variables := map[string]Variable{
@@ -69,8 +84,7 @@ func InitCommand(configPath, sourceRoot string) {
 	}
 
 	cfg := Config{
-		SourceRoot: configSourceRoot,
-		// TemplateRoot:  deprecated,
+		SourceRoot:    configSourceRoot,
 		Token:         map[string]string{"start": "{{", "end": "}}"},
 		IgnoreFolders: defaultIgnoreFolders,
 		IgnoreFiles:   defaultIgnoreFiles,
@@ -81,10 +95,57 @@ func InitCommand(configPath, sourceRoot string) {
 	}
 
 	if err := cfg.Save(configPath); err != nil {
-		fmt.Println("Error writing config file:", err)
-		return
+		return fmt.Errorf("writing config file: %w", err)
 	}
 	fmt.Printf("Config file written to %s\n", configPath)
+	return nil
+}
+
+// maxDelimiterWarnings caps the files listed by the init delimiter lint.
+const maxDelimiterWarnings = 10
+
+// findDelimiterUses lists, as "path:line", the first line of every text
+// file that would be scaffolded and already contains start and end.
+func findDelimiterUses(root, start, end string, scaffoldIgnore *ignoreTree) []string {
+	var uses []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if configIgnoreSource(rel, d.IsDir(), defaultIgnoreFolders, defaultIgnoreFiles) != "" || scaffoldIgnore.Ignored(rel, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			_ = scaffoldIgnore.enter(rel)
+			return nil
+		}
+		if matchesPatternList(rel, defaultStaticGlobs) {
+			return nil
+		}
+		if binary, err := looksBinary(path); err != nil || binary {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		for i, line := range strings.Split(string(data), "\n") {
+			if from := strings.Index(line, start); from >= 0 && strings.Contains(line[from+len(start):], end) {
+				uses = append(uses, fmt.Sprintf("%s:%d", rel, i+1))
+				break
+			}
+		}
+		return nil
+	})
+	return uses
 }
 
 func detectProjectName(root string) string {
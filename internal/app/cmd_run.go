@@ -1,8 +1,8 @@
 package app
 
 import (
+	"context"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -10,92 +10,108 @@ import (
 )
 
 // RunCommand scaffolds a project directly from source to output without an intermediate build step.
-func RunCommand(configPath, sourceRoot, outPath string, copyConfig bool) {
-	configPath = resolveConfigPath(configPath)
-	if strings.TrimSpace(outPath) == "" {
-		outPath = defaultGenerateOut
+func RunCommand(configPath, sourceRoot, outPath string, copyConfig bool) error {
+	_, err := Run(context.Background(), RunOptions{
+		ConfigPath: configPath,
+		SourceRoot: sourceRoot,
+		OutPath:    outPath,
+		CopyConfig: copyConfig,
+	})
+	return err
+}
+
+// Run scaffolds a project as described by opts and reports what was written.
+func Run(ctx context.Context, opts RunOptions) (*Result, error) {
+	streams := opts.Streams.withDefaults()
+	if err := checkOverwriteStrategy(opts.Overwrite); err != nil {
+		return nil, err
+	}
+	diffs := diffSelection{glob: strings.TrimSpace(opts.DiffGlob), limit: opts.DiffLimit}
+	if diffs.enabled() {
+		opts.DryRun = true
+	}
+	switch strings.ToLower(opts.PlanFormat) {
+	case "", PlanFormatText, PlanFormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown output format %q (want text or json)", opts.PlanFormat)
 	}
+	planOut := streams.Out
+	if opts.DryRun && strings.EqualFold(opts.PlanFormat, PlanFormatJSON) {
+		// Keep stdout machine-readable: prompts and progress go to stderr.
+		streams.Out = streams.ErrOut
+	}
+	configPath := resolveConfigPath(opts.ConfigPath)
 
 	cfg, err := LoadConfig(configPath)
 	if err != nil {
-		fmt.Println("Error loading config:", err)
-		return
+		return nil, fmt.Errorf("loading config: %w", err)
 	}
 
 	// Override source root if provided
-	if strings.TrimSpace(sourceRoot) != "" {
-		cfg.SourceRoot = sourceRoot
+	if strings.TrimSpace(opts.SourceRoot) != "" {
+		cfg.SourceRoot = opts.SourceRoot
+	}
+	if opts.UseGitignore {
+		cfg.UseGitignore = true
+	}
+	if err := cfg.applyProfile(opts.Profile); err != nil {
+		return nil, err
+	}
+	if err := checkStrict(cfg, opts.Strict); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(opts.Profile) != "" {
+		fmt.Fprintf(streams.Out, "Using profile %s\n", strings.TrimSpace(opts.Profile))
 	}
 
 	// Resolve SourceRoot
-	sourceRoot, err = filepath.Abs(cfg.SourceRoot)
+	sourceRoot, err := filepath.Abs(cfg.SourceRoot)
 	if err != nil {
-		fmt.Println("Error resolving source root:", err)
-		return
+		return nil, fmt.Errorf("resolving source root: %w", err)
 	}
 
 	// Collect variables
-	values, err := collectVariableValues(cfg.Variables)
+	values, err := collectVariableValues(ctx, cfg.Variables, opts.Values, streams)
 	if err != nil {
-		fmt.Println("Error collecting variable values:", err)
-		return
+		return nil, fmt.Errorf("collecting variable values: %w", err)
 	}
 
-	// Determine output path based on name variable if present
-	var nameVar string
-	if val, ok := values["name"]; ok {
-		nameVar = val
-	} else if val, ok := values["projectName"]; ok {
-		nameVar = val
-	} else if val, ok := values["PROJECT_NAME"]; ok {
-		nameVar = val
-	}
-
-	if strings.TrimSpace(nameVar) != "" {
-		// If outPath was just "." or default, append the name
-		// If outPath ends with the name already, don't append?
-		// The original logic was: outPath = filepath.Join(filepath.Dir(outPath), nameVar)
-		// But that assumes outPath was a placeholder.
-		// Let's stick to the original logic if it makes sense.
-		// Original: outPath = filepath.Join(filepath.Dir(outPath), strings.TrimSpace(nameVar))
-		// This effectively replaces the last segment of outPath with nameVar.
-		// If user said --out ./my-new-app, and name is "cool-app", it becomes ./cool-app.
-		// That seems a bit aggressive if the user explicitly set --out.
-		// But let's keep it consistent with previous behavior for now, or maybe improve it.
-		// If the user didn't specify --out (it's default), then we definitely want to use the name.
-		// If the user DID specify --out, maybe we should respect it?
-		// The original code did:
-		// if strings.TrimSpace(outPath) == "" { outPath = defaultGenerateOut }
-		// ...
-		// if nameVar != "" { outPath = filepath.Join(filepath.Dir(outPath), nameVar) }
-		// This overrides the last part of outPath.
-		// I'll keep it for compatibility/behavior preservation.
-		outPath = filepath.Join(filepath.Dir(outPath), strings.TrimSpace(nameVar))
+	// An explicit --out is used as given; otherwise the naming policy applies
+	outPath := opts.OutPath
+	if strings.TrimSpace(outPath) == "" {
+		if outPath, err = defaultOutputPath(cfg, values); err != nil {
+			return nil, err
+		}
 	}
 
 	outPath, err = filepath.Abs(outPath)
 	if err != nil {
-		fmt.Println("Error resolving output path:", err)
-		return
+		return nil, fmt.Errorf("resolving output path: %w", err)
 	}
 
-	if _, err := os.Stat(outPath); err == nil {
-		fmt.Printf("Output path %s already exists\n", outPath)
-		return
+	if _, err := os.Stat(outPath); err == nil && opts.Overwrite == "" && !opts.DryRun {
+		return nil, fmt.Errorf("output path %s already exists (use --force, --skip-existing, --backup or --interactive)", outPath)
 	}
 
-	fmt.Printf("Scaffolding from %s to %s...\n", sourceRoot, outPath)
+	fmt.Fprintf(streams.Out, "Scaffolding from %s to %s...\n", sourceRoot, outPath)
 
 	// Generate variations for automatic replacement
 	sourceName := filepath.Base(sourceRoot)
 	targetName := filepath.Base(outPath)
 
-	fmt.Printf("Detecting variations: %s -> %s\n", sourceName, targetName)
+	fmt.Fprintf(streams.Out, "Detecting variations: %s -> %s\n", sourceName, targetName)
 
 	sourceVars := generateVariations(sourceName)
 	targetVars := generateVariations(targetName)
 
-	for key, srcVal := range sourceVars {
+	// Iterate in a fixed order so that plans and outputs are reproducible
+	keys := make([]string, 0, len(sourceVars))
+	for key := range sourceVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		srcVal := sourceVars[key]
 		if tgtVal, ok := targetVars[key]; ok {
 			if srcVal == tgtVal {
 				continue
@@ -113,140 +129,162 @@ func RunCommand(configPath, sourceRoot, outPath string, copyConfig bool) {
 		}
 	}
 
-	// Sort replacements by length of Find string (descending) to avoid partial matches
-	sort.Slice(cfg.Replacements, func(i, j int) bool {
-		return len(cfg.Replacements[i].Find) > len(cfg.Replacements[j].Find)
-	})
-	sort.Slice(cfg.RenameRules, func(i, j int) bool {
-		return len(cfg.RenameRules[i].From) > len(cfg.RenameRules[j].From)
-	})
-
-	if err := scaffoldProject(cfg, sourceRoot, outPath, values); err != nil {
-		fmt.Println("Error scaffolding project:", err)
-		return
-	}
-
-	if copyConfig && configPath != "" {
-		src, err := os.ReadFile(configPath)
-		if err != nil {
-			fmt.Printf("Warning: Could not read config file to copy: %v\n", err)
-		} else {
-			dstPath := filepath.Join(outPath, filepath.Base(configPath))
-			if err := os.WriteFile(dstPath, src, 0644); err != nil {
-				fmt.Printf("Warning: Could not write config file: %v\n", err)
-			} else {
-				fmt.Printf("Copied config file to %s\n", dstPath)
-			}
-		}
-	}
-
-	fmt.Printf("Project generated at %s\n", outPath)
-}
-
-func scaffoldProject(cfg *Config, sourceRoot, outPath string, values map[string]string) error {
-	if err := os.MkdirAll(outPath, 0o755); err != nil {
-		return err
-	}
+	sortReplacementRules(cfg)
 
-	scaffoldIgnore := loadScaffoldIgnore(sourceRoot)
-	start, end := defaultTokenDelims(cfg.Token)
-
-	// Ensure we don't copy the config file itself if it's in sourceRoot
-	// (Though usually config is outside or we want to ignore it)
-	// cfg.IgnoreFiles = append(cfg.IgnoreFiles, filepath.Base(configPath)) // We don't have configPath here easily, but it's fine.
-
-	var templated, static int
-	walkErr := filepath.WalkDir(sourceRoot, func(path string, d fs.DirEntry, err error) error {
+	if opts.DryRun {
+		plan, err := planProject(ctx, cfg, sourceRoot, outPath, values)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("planning project: %w", err)
 		}
-		if path == sourceRoot {
-			return nil
+		if err := describePlan(cfg, plan, values, diffs); err != nil {
+			return nil, fmt.Errorf("planning project: %w", err)
 		}
-
-		// Skip the output directory if it's inside sourceRoot (to avoid infinite recursion)
-		if path == outPath || strings.HasPrefix(path, outPath+string(filepath.Separator)) {
-			if d.IsDir() {
-				return fs.SkipDir
-			}
-			return nil
+		if err := writePlan(planOut, plan, opts.PlanFormat); err != nil {
+			return nil, err
 		}
-
-		rel, err := filepath.Rel(sourceRoot, path)
-		if err != nil {
-			return err
-		}
-		rel = filepath.ToSlash(rel)
-
-		if MatchIgnore(rel, d.IsDir(), cfg.IgnoreFolders, cfg.IgnoreFiles, scaffoldIgnore) {
-			if d.IsDir() {
-				return fs.SkipDir
-			}
-			return nil
+		if opts.Verbose {
+			writeSkipReport(streams.Out, plan.Skipped, plan.SkippedBy)
 		}
+		res := &Result{OutPath: outPath, Skipped: plan.Skipped, SkippedBy: plan.SkippedBy, Unresolved: plan.Unresolved, Plan: plan}
+		return res, reportUnresolved(streams.ErrOut, plan.Unresolved, opts.Strict)
+	}
 
-		// 1. Apply RenameRules
-		renamedRel := applyRenameRules(rel, cfg.RenameRules)
+	if err := runHooks(ctx, cfg, HookPreGenerate, values, outPath, outPath, sourceRoot, streams); err != nil {
+		return nil, err
+	}
 
-		// 2. Apply Token Replacement to path
-		resolvedRel := replaceTokens(renamedRel, values, start, end)
+	stage, err := newStagingDir(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer stage.cleanup()
 
-		targetPath := filepath.Join(outPath, filepath.FromSlash(resolvedRel))
+	res := &Result{OutPath: outPath}
+	if err := scaffoldProject(ctx, cfg, sourceRoot, outPath, stage.path, values, res); err != nil {
+		return res, fmt.Errorf("scaffolding project: %w", err)
+	}
+	fmt.Fprintf(streams.Out, "Created %d templated file(s) and %d static asset(s)\n", len(res.FilesCreated), len(res.StaticCopied))
+	if opts.Verbose {
+		writeSkipReport(streams.Out, res.Skipped, res.SkippedBy)
+	}
+	if err := reportUnresolved(streams.ErrOut, res.Unresolved, opts.Strict); err != nil {
+		return res, err
+	}
 
-		if d.IsDir() {
-			return os.MkdirAll(targetPath, 0o755)
-		}
+	if opts.CopyConfig && configPath != "" {
+		copyConfigFile(configPath, stage.path, outPath, streams)
+	}
 
-		// Ensure parent dir exists
-		if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
-			return err
-		}
+	// Post-generation hooks run in the staging directory so that a failing
+	// hook leaves nothing behind at outPath.
+	if err := runHooks(ctx, cfg, HookPostGenerate, values, stage.path, outPath, stage.path, streams); err != nil {
+		return res, err
+	}
 
-		// Check if static
-		if matchesPatternList(rel, cfg.StaticFiles) {
-			info, err := d.Info()
-			if err != nil {
-				return err
-			}
-			if err := copyFile(path, targetPath, info.Mode()); err != nil {
-				return err
-			}
-			static++
-			return nil
-		}
+	if err := stage.commit(ctx, newConflictPolicy(opts.Overwrite, streams), res); err != nil {
+		return res, fmt.Errorf("moving project into place: %w", err)
+	}
+	writeConflictSummary(streams.Out, res)
 
-		// Templated file
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return err
-		}
+	fmt.Fprintf(streams.Out, "Project generated at %s\n", outPath)
+	return res, nil
+}
 
-		content := string(data)
+// copyConfigFile copies the config file at src into writeRoot; failures are
+// reported as warnings because the generated project is still usable.
+func copyConfigFile(src, writeRoot, outPath string, streams IOStreams) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		fmt.Fprintf(streams.ErrOut, "Warning: Could not read config file to copy: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(writeRoot, filepath.Base(src)), data, 0644); err != nil {
+		fmt.Fprintf(streams.ErrOut, "Warning: Could not write config file: %v\n", err)
+		return
+	}
+	fmt.Fprintf(streams.Out, "Copied config file to %s\n", filepath.Join(outPath, filepath.Base(src)))
+}
 
-		// Apply Config.Replacements first (e.g. "Banker" -> "{{PROJECT_NAME}}")
-		for _, repl := range cfg.Replacements {
-			if repl.Find == "" {
-				continue
-			}
-			content = strings.ReplaceAll(content, repl.Find, repl.ReplaceWith)
+// sortReplacementRules orders rename rules and literal replacements by length
+// of the searched string (descending) to avoid partial matches. Regex
+// replacements keep their place in the config: only the literal ones between
+// two regex replacements are sorted, so a regex sees the output of every
+// replacement listed before it.
+func sortReplacementRules(cfg *Config) {
+	repls := cfg.Replacements
+	for start := 0; start < len(repls); start++ {
+		end := start
+		for end < len(repls) && !repls[end].Regex {
+			end++
 		}
+		literal := repls[start:end]
+		sort.SliceStable(literal, func(i, j int) bool {
+			return len(literal[i].Find) > len(literal[j].Find)
+		})
+		start = end
+	}
+	sort.SliceStable(cfg.RenameRules, func(i, j int) bool {
+		return len(cfg.RenameRules[i].From) > len(cfg.RenameRules[j].From)
+	})
+}
 
-		content = replaceTokens(content, values, start, end)
-
-		info, err := d.Info()
+// defaultOutputPath names the output directory when --out is omitted: the
+// rendered outputName template if configured, otherwise the project name
+// variable (name, projectName or PROJECT_NAME), otherwise defaultGenerateOut.
+// The name must be usable as a single directory name on every platform.
+func defaultOutputPath(cfg *Config, values map[string]string) (string, error) {
+	var name string
+	if strings.TrimSpace(cfg.OutputName) != "" {
+		start, end := defaultTokenDelims(cfg.Token)
+		rendered, unresolved, err := expandTokens(cfg.OutputName, values, start, end)
 		if err != nil {
-			return err
+			return "", fmt.Errorf("outputName: %w", err)
 		}
-		if err := os.WriteFile(targetPath, []byte(content), info.Mode()); err != nil {
-			return err
+		name = strings.TrimSpace(rendered)
+		if len(unresolved) > 0 {
+			return "", fmt.Errorf("outputName %q has unresolved tokens: %q", cfg.OutputName, name)
 		}
-		templated++
-		return nil
-	})
+	} else if val, ok := values["name"]; ok {
+		name = val
+	} else if val, ok := values["projectName"]; ok {
+		name = val
+	} else if val, ok := values["PROJECT_NAME"]; ok {
+		name = val
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return defaultGenerateOut, nil
+	}
+	if err := checkDirName(name); err != nil {
+		return "", fmt.Errorf("output directory name %q: %w", name, err)
+	}
+	return filepath.Join(filepath.Dir(defaultGenerateOut), name), nil
+}
 
-	if walkErr != nil {
-		return walkErr
+// checkDirName rejects names that are not a single portable path segment.
+func checkDirName(name string) error {
+	if name == "." || name == ".." {
+		return fmt.Errorf("is not a directory name")
+	}
+	for _, r := range name {
+		switch {
+		case r == '/' || r == '\\':
+			return fmt.Errorf("must not contain path separators")
+		case r < 0x20 || r == 0x7f:
+			return fmt.Errorf("must not contain control characters")
+		case strings.ContainsRune(`<>:"|?*`, r):
+			return fmt.Errorf("must not contain %q", r)
+		}
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return fmt.Errorf("must not end with a dot or space")
+	}
+	stem := strings.ToUpper(strings.SplitN(name, ".", 2)[0])
+	switch stem {
+	case "CON", "PRN", "AUX", "NUL",
+		"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+		"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9":
+		return fmt.Errorf("is a reserved name on Windows")
 	}
-	fmt.Printf("Created %d templated file(s) and %d static asset(s)\n", templated, static)
 	return nil
 }
@@ -3,76 +3,187 @@ package app
 import (
 	"encoding/json"
 	"errors"
-	"io"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Variable struct {
-	Type        string `json:"type"`
-	Required    bool   `json:"required"`
-	Default     string `json:"default,omitempty"`
-	Description string `json:"description"`
-	From        string `json:"from,omitempty"`
-	Transform   string `json:"transform,omitempty"`
+	Type        string    `json:"type"`
+	Required    bool      `json:"required"`
+	Default     string    `json:"default,omitempty"`
+	Description string    `json:"description"`
+	From        string    `json:"from,omitempty"`
+	Transform   Transform `json:"transform,omitempty"`
+	Min         *int      `json:"min,omitempty"`
+	Max         *int      `json:"max,omitempty"`
+	Options     []string  `json:"options,omitempty"`
+	Separator   string    `json:"separator,omitempty"`
+	Pattern     string    `json:"pattern,omitempty"`
+}
+
+// UnmarshalJSON also accepts a number or boolean default, as YAML configs
+// write them unquoted (default: 8080, default: false).
+func (v *Variable) UnmarshalJSON(data []byte) error {
+	type plain Variable
+	var raw struct {
+		plain
+		Default any `json:"default,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*v = Variable(raw.plain)
+	switch d := raw.Default.(type) {
+	case nil:
+	case string:
+		v.Default = d
+	case float64, bool:
+		v.Default = fmt.Sprint(d)
+	default:
+		return fmt.Errorf("default must be a string, number or boolean")
+	}
+	return nil
+}
+
+// Transform names the transforms applied, in order, to a derived variable's
+// source value. It is written as a single name, a "|"-separated pipeline or,
+// in config files, a list of names.
+type Transform string
+
+// UnmarshalJSON accepts a string or a list of transform names.
+func (t *Transform) UnmarshalJSON(data []byte) error {
+	var steps []string
+	if err := json.Unmarshal(data, &steps); err == nil {
+		*t = Transform(strings.Join(steps, "|"))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("transform must be a string or a list of strings")
+	}
+	*t = Transform(s)
+	return nil
+}
+
+// UnmarshalYAML accepts a string or a list of transform names.
+func (t *Transform) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var steps []string
+		if err := node.Decode(&steps); err != nil {
+			return err
+		}
+		*t = Transform(strings.Join(steps, "|"))
+		return nil
+	}
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return fmt.Errorf("line %d: transform must be a string or a list of strings", node.Line)
+	}
+	*t = Transform(s)
+	return nil
+}
+
+// steps lists the transform names in the pipeline.
+func (t Transform) steps() []string {
+	var steps []string
+	for _, step := range strings.Split(string(t), "|") {
+		if step = strings.TrimSpace(step); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
 }
 
 type Replacement struct {
 	Find        string `json:"find"`
 	ReplaceWith string `json:"replaceWith"`
+	// Regex treats Find as a regular expression whose capture groups
+	// ReplaceWith can refer to as $1 or ${name}.
+	Regex bool `json:"regex,omitempty"`
+	// Include and Exclude scope the replacement to matching file paths.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+
+	re *regexp.Regexp `json:"-"`
 }
 
 type RenameRule struct {
 	From string `json:"from"`
 	To   string `json:"to"`
+	// Include and Exclude scope the rule to matching paths.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// TokenOverride sets different token delimiters for paths matching Glob,
+// e.g. for Helm charts that use {{ }} themselves.
+type TokenOverride struct {
+	Glob  string `json:"glob"`
+	Start string `json:"start"`
+	End   string `json:"end"`
 }
 
 type Hook struct {
-	Command string `json:"command"`
-	Cwd     string `json:"cwd"`
+	Command   string `json:"command"`
+	Cwd       string `json:"cwd"`
+	Timeout   string `json:"timeout,omitempty"`
+	OnFailure string `json:"onFailure,omitempty"`
 }
 
+// Config describes how a source project is scaffolded. Extends lists configs,
+// relative to this one, that it is merged over in order: lists are merged
+// without duplicates, maps key by key, and other values are overridden.
 type Config struct {
-	SourceRoot    string              `json:"sourceRoot"`
-	TemplateRoot  string              `json:"templateRoot"`
-	Token         map[string]string   `json:"token"`
-	IgnoreFolders []string            `json:"ignoreFolders"`
-	IgnoreFiles   []string            `json:"ignoreFiles"`
-	StaticFiles   []string            `json:"staticFiles"`
-	Variables     map[string]Variable `json:"variables"`
-	Replacements  []Replacement       `json:"replacements"`
-	RenameRules   []RenameRule        `json:"renameRules"`
-	Hooks         map[string][]Hook   `json:"hooks"`
+	Extends          []string            `json:"extends,omitempty"`
+	SourceRoot       string              `json:"sourceRoot"`
+	TemplateRoot     string              `json:"templateRoot"`
+	Token            map[string]string   `json:"token"`
+	IgnoreFolders    []string            `json:"ignoreFolders"`
+	IgnoreFiles      []string            `json:"ignoreFiles"`
+	StaticFiles      []string            `json:"staticFiles"`
+	Variables        map[string]Variable `json:"variables"`
+	Replacements     []Replacement       `json:"replacements"`
+	RenameRules      []RenameRule        `json:"renameRules"`
+	Hooks            map[string][]Hook   `json:"hooks"`
+	OutputName       string              `json:"outputName,omitempty"`
+	Engine           string              `json:"engine,omitempty"`
+	ConditionalPaths map[string]string   `json:"conditionalPaths,omitempty"`
+	TokenOverrides   []TokenOverride     `json:"tokenOverrides,omitempty"`
+	UseGitignore     bool                `json:"useGitignore,omitempty"`
+	Include          []string            `json:"include,omitempty"`
+	Profiles         map[string]Profile  `json:"profiles,omitempty"`
 }
 
+// LoadConfig reads the config at path, merged over the configs it extends
+// (see Config.Extends), and validates it.
 func LoadConfig(path string) (*Config, error) {
-	f, err := os.Open(path)
+	layer, err := loadConfigLayer(path, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	cfg := layer.cfg
 
-	data, err := io.ReadAll(f)
-	if err != nil {
+	cfg.applyDefaults()
+	if err := checkEngine(cfg.Engine); err != nil {
 		return nil, err
 	}
-
-	var cfg Config
-	switch strings.ToLower(filepath.Ext(path)) {
-	case ".yaml", ".yml":
-		if err := yaml.Unmarshal(data, &cfg); err != nil {
-			return nil, err
-		}
-	default:
-		if err := json.Unmarshal(data, &cfg); err != nil {
-			return nil, err
-		}
+	if _, err := compileConditionalPaths(cfg.ConditionalPaths); err != nil {
+		return nil, err
+	}
+	if err := checkTokenOverrides(cfg.TokenOverrides); err != nil {
+		return nil, err
+	}
+	if err := compileReplacements(cfg.Replacements); err != nil {
+		return nil, err
+	}
+	if err := checkProfiles(cfg.Profiles); err != nil {
+		return nil, err
 	}
-
-	cfg.applyDefaults()
 
 	// Resolve SourceRoot relative to the config file path
 	if !filepath.IsAbs(cfg.SourceRoot) {
@@ -92,20 +203,42 @@ func (cfg *Config) Save(path string) error {
 			return err
 		}
 	}
-	var (
-		data []byte
-		err  error
-	)
+	data, err := cfg.encode(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// encode renders cfg in the format implied by the extension of path. YAML is
+// encoded through JSON so that both formats use the json tags and omit the
+// same empty fields.
+func (cfg *Config) encode(path string) ([]byte, error) {
 	switch strings.ToLower(filepath.Ext(path)) {
 	case ".yaml", ".yml":
-		data, err = yaml.Marshal(cfg)
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return nil, err
+		}
+		// A node keeps the field order of the JSON document
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		clearNodeStyle(&doc)
+		return yaml.Marshal(&doc)
 	default:
-		data, err = json.MarshalIndent(cfg, "", "  ")
+		return json.MarshalIndent(cfg, "", "  ")
 	}
-	if err != nil {
-		return err
+}
+
+// clearNodeStyle drops the flow and quoting styles that JSON input gives a
+// YAML node, so that it is written in block style.
+func clearNodeStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearNodeStyle(child)
 	}
-	return os.WriteFile(path, data, 0o644)
 }
 
 // GetVariableValue returns the value for a variable, using override or default if not set
@@ -157,7 +290,6 @@ func (cfg *Config) applyDefaults() {
 	if strings.TrimSpace(cfg.SourceRoot) == "" {
 		cfg.SourceRoot = "."
 	}
-	// TemplateRoot is deprecated/unused in simplified version
 	if len(cfg.IgnoreFolders) == 0 {
 		cfg.IgnoreFolders = append([]string{}, defaultIgnoreFolders...)
 	}
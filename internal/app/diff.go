@@ -0,0 +1,199 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	diffContext = 3
+	// maxDiffCells bounds the LCS table; larger changed regions are shown as
+	// a full delete/insert instead.
+	maxDiffCells = 4_000_000
+)
+
+type diffLine struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// unifiedDiff renders a unified diff between a and b, or "" if they are equal.
+func unifiedDiff(fromName, toName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range groupHunks(lines, diffContext) {
+		oldStart, newStart := h.oldStart, h.newStart
+		var oldLen, newLen int
+		for _, l := range h.lines {
+			if l.kind != '+' {
+				oldLen++
+			}
+			if l.kind != '-' {
+				newLen++
+			}
+		}
+		if oldLen > 0 {
+			oldStart++
+		}
+		if newLen > 0 {
+			newStart++
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldLen), hunkRange(newStart, newLen))
+		for _, l := range h.lines {
+			sb.WriteByte(l.kind)
+			if strings.HasSuffix(l.text, "\n") {
+				sb.WriteString(l.text)
+			} else {
+				sb.WriteString(l.text)
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+func hunkRange(start, length int) string {
+	if length == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
+
+// splitLines splits s after each newline, keeping the terminators so that a
+// missing final newline can be reported.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a line edit script from a to b.
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var out []diffLine
+	for _, l := range a[:prefix] {
+		out = append(out, diffLine{' ', l})
+	}
+	out = append(out, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		out = append(out, diffLine{' ', l})
+	}
+	return out
+}
+
+func diffMiddle(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	var out []diffLine
+	if n*m == 0 || n*m > maxDiffCells {
+		for _, l := range a {
+			out = append(out, diffLine{'-', l})
+		}
+		for _, l := range b {
+			out = append(out, diffLine{'+', l})
+		}
+		return out
+	}
+
+	// lcs[i*(m+1)+j] is the LCS length of a[i:] and b[j:].
+	lcs := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+			out = append(out, diffLine{'-', a[i]})
+			i++
+		default:
+			out = append(out, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, diffLine{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		out = append(out, diffLine{'+', b[j]})
+	}
+	return out
+}
+
+type diffHunk struct {
+	oldStart, newStart int // zero-based line offsets of the first hunk line
+	lines              []diffLine
+}
+
+// groupHunks splits an edit script into hunks with up to context unchanged
+// lines around each change; changes closer than 2*context share a hunk.
+func groupHunks(lines []diffLine, context int) []diffHunk {
+	// Line offsets in the old and new file before lines[i]
+	oldAt := make([]int, len(lines)+1)
+	newAt := make([]int, len(lines)+1)
+	for i, l := range lines {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if l.kind != '+' {
+			oldAt[i+1]++
+		}
+		if l.kind != '-' {
+			newAt[i+1]++
+		}
+	}
+
+	var hunks []diffHunk
+	for i := 0; i < len(lines); {
+		if lines[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(lines); {
+			if lines[j].kind != ' ' {
+				j++
+				end = j
+				continue
+			}
+			k := j
+			for k < len(lines) && lines[k].kind == ' ' {
+				k++
+			}
+			if k == len(lines) || k-j > 2*context {
+				break
+			}
+			j = k
+		}
+		stop := min(end+context, len(lines))
+		hunks = append(hunks, diffHunk{oldStart: oldAt[start], newStart: newAt[start], lines: lines[start:stop]})
+		i = stop
+	}
+	return hunks
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Plan output formats accepted by RunOptions.PlanFormat.
+const (
+	PlanFormatText = "text"
+	PlanFormatJSON = "json"
+)
+
+// diffSelection chooses the templated files that get a unified diff: those
+// whose source path matches glob (all files when empty), at most limit of
+// them when limit is positive.
+type diffSelection struct {
+	glob  string
+	limit int
+}
+
+func (d diffSelection) enabled() bool {
+	return d.glob != "" || d.limit > 0
+}
+
+// describePlan fills in, for every templated operation, which replacements
+// would fire and how many times, plus a unified diff for the selected files.
+func describePlan(cfg *Config, plan *Plan, values map[string]string, diffs diffSelection) error {
+	diffed := 0
+	for i := range plan.Operations {
+		op := &plan.Operations[i]
+		if op.Kind != OpTemplated {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(plan.SourceRoot, filepath.FromSlash(op.Source)))
+		if err != nil {
+			return err
+		}
+		content, hits, unresolved, err := renderContent(cfg, op.Source, string(data), values)
+		if err != nil {
+			return err
+		}
+		op.Replacements = hits
+		for _, tok := range unresolved {
+			tok.Path = op.Destination
+			plan.Unresolved = append(plan.Unresolved, tok)
+		}
+
+		if !diffs.enabled() || (diffs.limit > 0 && diffed >= diffs.limit) {
+			continue
+		}
+		if diffs.glob != "" && !matchGlob(op.Source, diffs.glob) {
+			continue
+		}
+		if op.Diff = unifiedDiff("a/"+op.Source, "b/"+op.Destination, string(data), content); op.Diff != "" {
+			diffed++
+		}
+	}
+	return nil
+}
+
+// writePlan prints a described plan in the requested format.
+func writePlan(w io.Writer, plan *Plan, format string) error {
+	switch strings.ToLower(format) {
+	case "", PlanFormatText:
+		writePlanText(w, plan)
+		writeDiffs(w, plan)
+		return nil
+	case PlanFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	default:
+		return fmt.Errorf("unknown output format %q (want text or json)", format)
+	}
+}
+
+func writePlanText(w io.Writer, plan *Plan) {
+	fmt.Fprintf(w, "Plan: %s -> %s\n", plan.SourceRoot, plan.OutPath)
+	counts := map[string]int{}
+	for _, op := range plan.Operations {
+		counts[op.Kind]++
+		dest := op.Destination
+		if op.Kind == OpDir {
+			dest += "/"
+		}
+		if op.Source == op.Destination {
+			fmt.Fprintf(w, "  %-9s  %s\n", op.Kind, dest)
+		} else {
+			fmt.Fprintf(w, "  %-9s  %s -> %s\n", op.Kind, op.Source, dest)
+		}
+		for _, hit := range op.Replacements {
+			fmt.Fprintf(w, "  %-9s    %q -> %q (%d)\n", "", hit.Find, hit.ReplaceWith, hit.Count)
+		}
+	}
+	if len(plan.Skipped) > 0 {
+		fmt.Fprintln(w, "Skipped:")
+		for _, path := range plan.Skipped {
+			fmt.Fprintf(w, "  %s\n", path)
+		}
+	}
+	if len(plan.Unresolved) > 0 {
+		fmt.Fprintln(w, "Unresolved tokens:")
+		for _, tok := range plan.Unresolved {
+			fmt.Fprintf(w, "  %s\n", tok)
+		}
+	}
+	fmt.Fprintf(w, "%d templated, %d static, %d dir(s), %d skipped\n",
+		counts[OpTemplated], counts[OpStatic], counts[OpDir], len(plan.Skipped))
+}
+
+// writeDiffs prints the unified diffs collected by describePlan.
+func writeDiffs(w io.Writer, plan *Plan) {
+	for _, op := range plan.Operations {
+		if op.Diff != "" {
+			fmt.Fprintf(w, "\n%s", op.Diff)
+		}
+	}
+}
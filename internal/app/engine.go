@@ -0,0 +1,106 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Rendering engines accepted by Config.Engine.
+const (
+	EngineTokens     = "tokens"
+	EngineGoTemplate = "go-template"
+)
+
+func checkEngine(engine string) error {
+	switch engine {
+	case "", EngineTokens, EngineGoTemplate:
+		return nil
+	default:
+		return fmt.Errorf("unknown engine %q (want %s or %s)", engine, EngineTokens, EngineGoTemplate)
+	}
+}
+
+// checkStrict rejects strict mode with the go-template engine. Its renderer
+// already fails on unknown variables and writes escaped delimiters on
+// purpose, so no unresolved tokens are ever reported for strict to act on.
+func checkStrict(cfg *Config, strict bool) error {
+	if strict && cfg.Engine == EngineGoTemplate {
+		return fmt.Errorf("--strict only applies to the %s engine; %s already fails on unknown variables", EngineTokens, EngineGoTemplate)
+	}
+	return nil
+}
+
+// templateFuncs exposes the name transforms to go-template templates.
+var templateFuncs = template.FuncMap{
+	"slugify":      func(s string) string { return slugify(s, '-') },
+	"toPascalCase": func(s string) string { return toPascalCase(splitIntoWords(s)) },
+	"toCamelCase":  func(s string) string { return toCamelCase(splitIntoWords(s)) },
+	"toSnakeCase":  func(s string) string { return toSnakeCase(splitIntoWords(s)) },
+	"titleCase":    titleCase,
+}
+
+// renderTokens resolves variable tokens in text using the configured engine
+// and lists the tokens left unresolved, without their Path. name is the
+// source path the text belongs to; it selects the token delimiters and
+// identifies the text in error messages. With nil values, as when building a
+// template skeleton, text is left untouched.
+func renderTokens(cfg *Config, name, text string, values map[string]string) (string, []UnresolvedToken, error) {
+	start, end := tokenDelimsFor(cfg, name)
+	if cfg.Engine != EngineGoTemplate {
+		out, unresolved, err := expandTokens(text, values, start, end)
+		var tokErr *tokenError
+		if errors.As(err, &tokErr) {
+			return "", nil, fmt.Errorf("%s:%d: %s", name, tokErr.line, tokErr.msg)
+		}
+		return out, unresolved, err
+	}
+	if values == nil {
+		return text, nil, nil
+	}
+	// Template errors read "template: <name>:<line>: ...", so the source
+	// path and line are part of every message.
+	tmpl, err := template.New(name).Delims(start, end).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", nil, err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, templateData(cfg.Variables, values)); err != nil {
+		return "", nil, err
+	}
+	return b.String(), nil, nil
+}
+
+// templateData converts validated values to their variable types so that
+// bools work in {{if}} and lists in {{range}}; other values stay strings.
+func templateData(vars map[string]Variable, values map[string]string) map[string]any {
+	data := make(map[string]any, len(values))
+	for name, val := range values {
+		data[name] = val
+		v, ok := vars[name]
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(v.Type) {
+		case VarBool:
+			data[name] = val == "true"
+		case VarInt:
+			if n, err := strconv.Atoi(val); err == nil {
+				data[name] = n
+			}
+		case VarList:
+			sep := v.Separator
+			if sep == "" {
+				sep = defaultListSeparator
+			}
+			var items []string
+			if val != "" {
+				items = strings.Split(val, sep)
+			}
+			data[name] = items
+		}
+	}
+	return data
+}
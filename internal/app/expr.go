@@ -0,0 +1,255 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// condition is a parsed boolean expression over variable values, as used by
+// conditionalPaths. It supports ==, !=, &&, ||, !, parentheses, quoted
+// strings, the literals true and false, numbers and bare variable names.
+// Operands are compared as strings; a bare operand is true unless it is
+// empty, "false", "no", "off" or "0".
+type condition struct {
+	src  string
+	root exprNode
+}
+
+type exprNode interface {
+	eval(values map[string]string) (string, error)
+}
+
+type (
+	literalNode  string
+	variableNode string
+	notNode      struct{ x exprNode }
+	binaryNode   struct {
+		op   string
+		l, r exprNode
+	}
+)
+
+func (n literalNode) eval(map[string]string) (string, error) { return string(n), nil }
+
+func (n variableNode) eval(values map[string]string) (string, error) {
+	val, ok := values[string(n)]
+	if !ok {
+		return "", fmt.Errorf("unknown variable %s (quote string literals)", string(n))
+	}
+	return val, nil
+}
+
+func (n notNode) eval(values map[string]string) (string, error) {
+	x, err := n.x.eval(values)
+	if err != nil {
+		return "", err
+	}
+	return boolString(!truthy(x)), nil
+}
+
+func (n binaryNode) eval(values map[string]string) (string, error) {
+	l, err := n.l.eval(values)
+	if err != nil {
+		return "", err
+	}
+	// && and || short-circuit
+	switch {
+	case n.op == "&&" && !truthy(l):
+		return "false", nil
+	case n.op == "||" && truthy(l):
+		return "true", nil
+	}
+	r, err := n.r.eval(values)
+	if err != nil {
+		return "", err
+	}
+	switch n.op {
+	case "==":
+		return boolString(l == r), nil
+	case "!=":
+		return boolString(l != r), nil
+	default:
+		return boolString(truthy(r)), nil
+	}
+}
+
+func truthy(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "false", "no", "off", "0":
+		return false
+	}
+	return true
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// parseCondition parses src into a condition.
+func parseCondition(src string) (*condition, error) {
+	p := &exprParser{src: src}
+	if err := p.lex(); err != nil {
+		return nil, fmt.Errorf("expression %q: %w", src, err)
+	}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("expression %q is empty", src)
+	}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", src, err)
+	}
+	return &condition{src: src, root: root}, nil
+}
+
+// eval reports whether the condition holds for values.
+func (c *condition) eval(values map[string]string) (bool, error) {
+	val, err := c.root.eval(values)
+	if err != nil {
+		return false, fmt.Errorf("expression %q: %w", c.src, err)
+	}
+	return truthy(val), nil
+}
+
+type exprToken struct {
+	kind string // "op", "word" or "string"
+	text string
+}
+
+type exprParser struct {
+	src    string
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) lex() error {
+	s := p.src
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			p.tokens = append(p.tokens, exprToken{"op", s[i : i+1]})
+			i++
+		case strings.HasPrefix(s[i:], "==") || strings.HasPrefix(s[i:], "!=") ||
+			strings.HasPrefix(s[i:], "&&") || strings.HasPrefix(s[i:], "||"):
+			p.tokens = append(p.tokens, exprToken{"op", s[i : i+2]})
+			i += 2
+		case c == '!':
+			p.tokens = append(p.tokens, exprToken{"op", "!"})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return fmt.Errorf("unterminated string at offset %d", i)
+			}
+			p.tokens = append(p.tokens, exprToken{"string", s[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			j := i
+			for j < len(s) && isWordRune(rune(s[j])) {
+				j++
+			}
+			if j == i {
+				return fmt.Errorf("unexpected %q at offset %d", s[i:i+1], i)
+			}
+			p.tokens = append(p.tokens, exprToken{"word", s[i:j]})
+			i = j
+		}
+	}
+	return nil
+}
+
+func isWordRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.')
+}
+
+func (p *exprParser) peek(op string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == "op" && p.tokens[p.pos].text == op
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary("||", p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary("&&", p.parseUnary)
+}
+
+func (p *exprParser) parseBinary(op string, next func() (exprNode, error)) (exprNode, error) {
+	l, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek(op) {
+		p.pos++
+		r, err := next()
+		if err != nil {
+			return nil, err
+		}
+		l = binaryNode{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peek("!") {
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	}
+	l, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!="} {
+		if p.peek(op) {
+			p.pos++
+			r, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			return binaryNode{op: op, l: l, r: r}, nil
+		}
+	}
+	return l, nil
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	switch {
+	case tok.kind == "string":
+		return literalNode(tok.text), nil
+	case tok.kind == "word":
+		if tok.text == "true" || tok.text == "false" || unicode.IsDigit(rune(tok.text[0])) {
+			return literalNode(tok.text), nil
+		}
+		return variableNode(tok.text), nil
+	case tok.text == "(":
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return x, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// errExtendsCycle is reported when configs extend each other.
+var errExtendsCycle = errors.New("config extends itself")
+
+// configLayer is one config file merged with the files it extends. raw is
+// the decoded document; its keys tell which fields the files set, so that a
+// file can also override a field with its zero value.
+type configLayer struct {
+	cfg Config
+	raw map[string]any
+}
+
+// loadConfigLayer reads the config at path and merges it over the configs
+// it extends, which are resolved relative to path. chain lists the files
+// being loaded, to detect cycles.
+func loadConfigLayer(path string, chain []string) (*configLayer, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, loading := range chain {
+		if loading == abs {
+			cycle := append(append([]string{}, chain[i:]...), abs)
+			return nil, fmt.Errorf("%w: %s", errExtendsCycle, strings.Join(cycle, " -> "))
+		}
+	}
+	chain = append(chain, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file configLayer
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		// YAML is decoded through JSON so that both formats use the json
+		// tags, matched case-insensitively like lookupField does
+		if err := yaml.Unmarshal(data, &file.raw); err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(file.raw); err != nil {
+			return nil, err
+		}
+	default:
+		if err := json.Unmarshal(data, &file.raw); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(data, &file.cfg); err != nil {
+		return nil, err
+	}
+	if len(chain) > 1 {
+		// Paths in an extended config are relative to that config
+		for _, field := range []*string{&file.cfg.SourceRoot, &file.cfg.TemplateRoot} {
+			if *field != "" && !filepath.IsAbs(*field) {
+				*field = filepath.Join(filepath.Dir(abs), *field)
+			}
+		}
+	}
+
+	merged := &configLayer{raw: map[string]any{}}
+	for _, parent := range file.cfg.Extends {
+		if !filepath.IsAbs(parent) {
+			parent = filepath.Join(filepath.Dir(path), parent)
+		}
+		base, err := loadConfigLayer(parent, chain)
+		if err != nil {
+			if errors.Is(err, errExtendsCycle) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("extending %s: %w", parent, err)
+		}
+		merged.merge(base)
+	}
+	merged.merge(&file)
+	merged.cfg.Extends = nil
+	return merged, nil
+}
+
+// merge applies src over l: lists are merged without duplicates, maps are
+// merged key by key and other values set by src replace those of l.
+func (l *configLayer) merge(src *configLayer) {
+	mergeValue(reflect.ValueOf(&l.cfg).Elem(), reflect.ValueOf(&src.cfg).Elem(), src.raw)
+	mergeRaw(l.raw, src.raw)
+}
+
+// mergeValue merges src into dst, following the fields and keys that raw,
+// the document src was decoded from, sets.
+func mergeValue(dst, src reflect.Value, raw any) {
+	switch dst.Kind() {
+	case reflect.Struct:
+		doc, _ := raw.(map[string]any)
+		t := dst.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if sub, ok := lookupField(doc, field); ok {
+				mergeValue(dst.Field(i), src.Field(i), sub)
+			}
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), src.Len()))
+		}
+		doc, _ := raw.(map[string]any)
+		iter := src.MapRange()
+		for iter.Next() {
+			key, value := iter.Key(), iter.Value()
+			existing := dst.MapIndex(key)
+			if !existing.IsValid() {
+				dst.SetMapIndex(key, value)
+				continue
+			}
+			merged := reflect.New(value.Type()).Elem()
+			merged.Set(existing)
+			mergeValue(merged, value, doc[key.String()])
+			dst.SetMapIndex(key, merged)
+		}
+	case reflect.Slice:
+		for i := 0; i < src.Len(); i++ {
+			item := src.Index(i)
+			if !containsValue(dst, item) {
+				dst.Set(reflect.Append(dst, item))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// lookupField returns the document value for a struct field. Keys match the
+// field's JSON name case-insensitively, since YAML configs spell them in
+// lower case.
+func lookupField(doc map[string]any, field reflect.StructField) (any, bool) {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return nil, false
+	}
+	for key, value := range doc {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func containsValue(list, item reflect.Value) bool {
+	for i := 0; i < list.Len(); i++ {
+		if reflect.DeepEqual(list.Index(i).Interface(), item.Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeRaw adds the keys of src to dst, so that dst records every field set
+// by either document. Keys are matched case-insensitively like lookupField.
+func mergeRaw(dst, src map[string]any) {
+	for key, value := range src {
+		for existing := range dst {
+			if strings.EqualFold(existing, key) {
+				key = existing
+				break
+			}
+		}
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeRaw(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
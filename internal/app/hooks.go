@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Hook phases recognised in Config.Hooks.
+const (
+	HookPreGenerate       = "preGenerate"
+	HookPostGenerate      = "postGenerate"
+	HookPreBuildTemplate  = "preBuildTemplate"
+	HookPostBuildTemplate = "postBuildTemplate"
+)
+
+const defaultHookTimeout = 10 * time.Minute
+
+// runHooks executes the hooks registered for phase in order. Command and Cwd are
+// token-expanded with values plus the built-in TARGET_DIR, the directory being
+// written, and OUTPUT_DIR, where the result ends up; a relative or empty Cwd is
+// resolved against defaultCwd. Post-phase hooks run before the staging
+// directory is moved into place, so for them TARGET_DIR is the staging
+// directory and OUTPUT_DIR the final output path.
+func runHooks(ctx context.Context, cfg *Config, phase string, values map[string]string, targetDir, outputDir, defaultCwd string, streams IOStreams) error {
+	hooks := cfg.Hooks[phase]
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	vars := make(map[string]string, len(values)+2)
+	for k, v := range values {
+		vars[k] = v
+	}
+	vars["TARGET_DIR"] = targetDir
+	vars["OUTPUT_DIR"] = outputDir
+	start, end := defaultTokenDelims(cfg.Token)
+
+	for i, hook := range hooks {
+		command, err := replaceTokens(hook.Command, vars, start, end)
+		if err != nil {
+			return fmt.Errorf("%s hook %d: command: %w", phase, i+1, err)
+		}
+		if command = strings.TrimSpace(command); command == "" {
+			continue
+		}
+		cwd, err := replaceTokens(hook.Cwd, vars, start, end)
+		if err != nil {
+			return fmt.Errorf("%s hook %d: cwd: %w", phase, i+1, err)
+		}
+		if cwd = strings.TrimSpace(cwd); cwd == "" {
+			cwd = defaultCwd
+		} else if !filepath.IsAbs(cwd) {
+			cwd = filepath.Join(defaultCwd, cwd)
+		}
+
+		timeout := defaultHookTimeout
+		if strings.TrimSpace(hook.Timeout) != "" {
+			d, err := time.ParseDuration(hook.Timeout)
+			if err != nil {
+				return fmt.Errorf("%s hook %d: invalid timeout %q: %w", phase, i+1, hook.Timeout, err)
+			}
+			timeout = d
+		}
+
+		var abort bool
+		switch strings.ToLower(strings.TrimSpace(hook.OnFailure)) {
+		case "", "abort":
+			abort = true
+		case "continue":
+			abort = false
+		default:
+			return fmt.Errorf("%s hook %d: unknown onFailure policy %q (want abort or continue)", phase, i+1, hook.OnFailure)
+		}
+
+		fmt.Fprintf(streams.Out, "Running %s hook: %s (in %s)\n", phase, command, cwd)
+		if err := runHookCommand(ctx, command, cwd, timeout, streams); err != nil {
+			if abort {
+				return fmt.Errorf("%s hook %q failed: %w", phase, command, err)
+			}
+			fmt.Fprintf(streams.ErrOut, "Warning: %s hook %q failed: %v (continuing)\n", phase, command, err)
+		}
+	}
+	return nil
+}
+
+func runHookCommand(ctx context.Context, command, cwd string, timeout time.Duration, streams IOStreams) error {
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(hookCtx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(hookCtx, "sh", "-c", command)
+	}
+	cmd.Dir = cwd
+	cmd.Stdout = streams.Out
+	cmd.Stderr = streams.ErrOut
+	setProcessGroup(cmd)
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(hookCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// generateHooks returns the subset of hooks that apply when consuming a template.
+func generateHooks(hooks map[string][]Hook) map[string][]Hook {
+	result := map[string][]Hook{}
+	for _, phase := range []string{HookPreGenerate, HookPostGenerate} {
+		if len(hooks[phase]) > 0 {
+			result[phase] = hooks[phase]
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
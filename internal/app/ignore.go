@@ -0,0 +1,226 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Ignore files read from the source tree. The git ones are only read with
+// Config.UseGitignore.
+const (
+	scaffoldIgnoreFile = ".scaffoldignore"
+	gitIgnoreFile      = ".gitignore"
+	gitInfoExclude     = ".git/info/exclude"
+)
+
+// IgnoreRule is one pattern of an ignore file, with gitignore semantics: a
+// leading "!" re-includes what earlier rules excluded, a trailing "/" only
+// matches directories, and a pattern with a "/" other than a trailing one is
+// anchored to the directory of its file; other patterns match names at any
+// depth below it.
+type IgnoreRule struct {
+	// Pattern is the pattern as written.
+	Pattern string
+	// Source is the ignore file, relative to the source root, and Line the
+	// 1-based line the pattern is on.
+	Source string
+	Line   int
+
+	dir      string
+	glob     string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+func (r IgnoreRule) String() string {
+	return fmt.Sprintf("%s:%d: %s", r.Source, r.Line, r.Pattern)
+}
+
+// ParseIgnoreRules parses the contents of the ignore file at source, a
+// slash-separated path relative to the source root. Blank lines and lines
+// starting with "#" are skipped; "\#" and "\!" start patterns with those
+// characters literally.
+func ParseIgnoreRules(source, text string) []IgnoreRule {
+	source = filepath.ToSlash(source)
+	dir := path.Dir(source)
+	if dir == "." {
+		dir = ""
+	}
+	return parseIgnoreRules(source, dir, text)
+}
+
+// parseIgnoreRules parses rules that are relative to dir rather than to the
+// directory of source, as for .git/info/exclude.
+func parseIgnoreRules(source, dir, text string) []IgnoreRule {
+	var rules []IgnoreRule
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		// Trailing spaces are dropped unless escaped with a backslash
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+			line = line[:len(line)-1]
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := IgnoreRule{Pattern: line, Source: source, Line: i + 1, dir: dir}
+		glob := line
+		if strings.HasPrefix(glob, "!") {
+			rule.negate = true
+			glob = glob[1:]
+		}
+		if strings.HasSuffix(glob, "/") {
+			rule.dirOnly = true
+			glob = strings.TrimRight(glob, "/")
+		}
+		if strings.Contains(glob, "/") {
+			rule.anchored = true
+			glob = strings.TrimPrefix(glob, "/")
+		}
+		if glob == "" {
+			continue
+		}
+		rule.glob = glob
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matches reports whether the rule's pattern matches rel, ignoring negation.
+func (r IgnoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.dir != "" {
+		if !strings.HasPrefix(rel, r.dir+"/") {
+			return false
+		}
+		rel = rel[len(r.dir)+1:]
+	}
+	if !r.anchored {
+		rel = path.Base(rel)
+	}
+	matched, err := doublestar.Match(r.glob, rel)
+	if err != nil || !matched {
+		return false
+	}
+	// "dir/**" matches everything inside dir but, unlike in doublestar, not
+	// dir itself
+	if prefix, ok := strings.CutSuffix(r.glob, "/**"); ok && prefix != "**" {
+		if self, _ := doublestar.Match(prefix, rel); self {
+			return false
+		}
+	}
+	return true
+}
+
+// IgnoreMatcher applies ignore rules in order; the last matching rule decides.
+// As in git, a path cannot be re-included when one of its parent directories
+// is excluded.
+type IgnoreMatcher struct {
+	rules []IgnoreRule
+}
+
+// NewIgnoreMatcher returns a matcher for rules, listed in increasing
+// precedence.
+func NewIgnoreMatcher(rules ...IgnoreRule) *IgnoreMatcher {
+	return &IgnoreMatcher{rules: rules}
+}
+
+// Add appends rules, which take precedence over the existing ones.
+func (m *IgnoreMatcher) Add(rules ...IgnoreRule) {
+	m.rules = append(m.rules, rules...)
+}
+
+// Rules lists the matcher's rules in increasing precedence.
+func (m *IgnoreMatcher) Rules() []IgnoreRule {
+	return m.rules
+}
+
+// Match reports whether the slash-separated path rel is ignored, and the
+// rule that decided it. When no rule matches, the rule is the zero value.
+func (m *IgnoreMatcher) Match(rel string, isDir bool) (IgnoreRule, bool) {
+	rel = filepath.ToSlash(rel)
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if rule, ignored := m.matchSelf(strings.Join(parts[:i], "/"), true); ignored {
+			return rule, true
+		}
+	}
+	return m.matchSelf(rel, isDir)
+}
+
+// Ignored reports whether rel is ignored.
+func (m *IgnoreMatcher) Ignored(rel string, isDir bool) bool {
+	_, ignored := m.Match(rel, isDir)
+	return ignored
+}
+
+func (m *IgnoreMatcher) matchSelf(rel string, isDir bool) (IgnoreRule, bool) {
+	var (
+		decided IgnoreRule
+		found   bool
+	)
+	for _, rule := range m.rules {
+		if rule.matches(rel, isDir) {
+			decided, found = rule, true
+		}
+	}
+	return decided, found && !decided.negate
+}
+
+// ignoreTree is an IgnoreMatcher fed with the ignore files of a source tree;
+// each directory's files are added by enter as the walk reaches it.
+type ignoreTree struct {
+	*IgnoreMatcher
+	root  string
+	names []string
+}
+
+// newIgnoreTree loads the ignore files at the top of root: .scaffoldignore
+// and, with gitignore set, .git/info/exclude and .gitignore.
+func newIgnoreTree(root string, gitignore bool) (*ignoreTree, error) {
+	t := &ignoreTree{IgnoreMatcher: NewIgnoreMatcher(), root: root, names: []string{scaffoldIgnoreFile}}
+	if gitignore {
+		// .scaffoldignore comes last so that it can re-include paths
+		t.names = []string{gitIgnoreFile, scaffoldIgnoreFile}
+		if err := t.load(gitInfoExclude, ""); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.enter(""); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// enter adds the rules of the ignore files in dir, relative to the root.
+func (t *ignoreTree) enter(dir string) error {
+	for _, name := range t.names {
+		if err := t.load(path.Join(filepath.ToSlash(dir), name), dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load adds the rules of the ignore file source, if there is one, with
+// patterns relative to dir.
+func (t *ignoreTree) load(source, dir string) error {
+	data, err := os.ReadFile(filepath.Join(t.root, filepath.FromSlash(source)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", source, err)
+	}
+	t.Add(parseIgnoreRules(source, filepath.ToSlash(dir), string(data))...)
+	return nil
+}
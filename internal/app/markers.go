@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markerPrefix starts a marker comment such as "// scaffo:if ENABLE_AUTH".
+const markerPrefix = "scaffo:"
+
+// markerBlock is an open scaffo:if block while processing markers.
+type markerBlock struct {
+	line     int
+	parentOn bool // whether the enclosing block emits lines
+	cond     bool // whether the if condition held
+	inElse   bool
+}
+
+func (b markerBlock) on() bool {
+	return b.parentOn && b.cond != b.inElse
+}
+
+// processMarkers keeps or drops the sections of a templated file enclosed in
+// scaffo:if / scaffo:else / scaffo:endif marker comments. Markers stand on a
+// line of their own as "// scaffo:...", "# scaffo:..." or
+// "<!-- scaffo:... -->" and are stripped from the output. Blocks may nest;
+// conditions use the conditionalPaths expression syntax and are only
+// evaluated when the enclosing block is kept. Errors name source and line.
+func processMarkers(source, content string, values map[string]string) (string, error) {
+	if !strings.Contains(content, markerPrefix) {
+		return content, nil
+	}
+	var (
+		b     strings.Builder
+		stack []markerBlock
+	)
+	on := func() bool { return len(stack) == 0 || stack[len(stack)-1].on() }
+
+	for i, line := range strings.SplitAfter(content, "\n") {
+		lineNo := i + 1
+		directive, arg, ok := parseMarker(line)
+		if !ok {
+			if on() {
+				b.WriteString(line)
+			}
+			continue
+		}
+		switch directive {
+		case "if":
+			cond, err := parseCondition(arg)
+			if err != nil {
+				return "", fmt.Errorf("%s:%d: %w", source, lineNo, err)
+			}
+			block := markerBlock{line: lineNo, parentOn: on()}
+			if block.parentOn {
+				if block.cond, err = cond.eval(values); err != nil {
+					return "", fmt.Errorf("%s:%d: %w", source, lineNo, err)
+				}
+			}
+			stack = append(stack, block)
+		case "else":
+			if len(stack) == 0 {
+				return "", fmt.Errorf("%s:%d: scaffo:else without scaffo:if", source, lineNo)
+			}
+			top := &stack[len(stack)-1]
+			if top.inElse {
+				return "", fmt.Errorf("%s:%d: second scaffo:else for scaffo:if on line %d", source, lineNo, top.line)
+			}
+			top.inElse = true
+		case "endif":
+			if len(stack) == 0 {
+				return "", fmt.Errorf("%s:%d: scaffo:endif without scaffo:if", source, lineNo)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			return "", fmt.Errorf("%s:%d: unknown marker scaffo:%s", source, lineNo, directive)
+		}
+	}
+	if len(stack) > 0 {
+		return "", fmt.Errorf("%s:%d: scaffo:if without scaffo:endif", source, stack[len(stack)-1].line)
+	}
+	return b.String(), nil
+}
+
+// parseMarker recognises a line that consists only of a marker comment and
+// returns its directive and argument.
+func parseMarker(line string) (directive, arg string, ok bool) {
+	text := strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(text, "//"):
+		text = text[2:]
+	case strings.HasPrefix(text, "#"):
+		text = text[1:]
+	case len(text) >= 7 && strings.HasPrefix(text, "<!--") && strings.HasSuffix(text, "-->"):
+		text = text[4 : len(text)-3]
+	default:
+		return "", "", false
+	}
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, markerPrefix) {
+		return "", "", false
+	}
+	text = text[len(markerPrefix):]
+	if i := strings.IndexAny(text, " \t"); i >= 0 {
+		return text[:i], strings.TrimSpace(text[i:]), true
+	}
+	return text, "", true
+}
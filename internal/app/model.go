@@ -1,5 +1,11 @@
 package app
 
+import (
+	"io"
+	"os"
+	"time"
+)
+
 // Add additional model types for variable discovery, file analysis, etc.
 
 // CandidateVariable represents a discovered variable in the source project
@@ -9,3 +15,122 @@ type CandidateVariable struct {
 	Occurrences int
 	Example     string
 }
+
+// TemplateMetadata is written next to a built template skeleton so that
+// GenerateCommand knows how to consume it.
+type TemplateMetadata struct {
+	Name             string              `json:"name"`
+	CreatedAt        time.Time           `json:"createdAt"`
+	Token            map[string]string   `json:"token"`
+	StaticFiles      []string            `json:"staticFiles"`
+	Variables        map[string]Variable `json:"variables"`
+	Hooks            map[string][]Hook   `json:"hooks,omitempty"`
+	OutputName       string              `json:"outputName,omitempty"`
+	Engine           string              `json:"engine,omitempty"`
+	ConditionalPaths map[string]string   `json:"conditionalPaths,omitempty"`
+	TokenOverrides   []TokenOverride     `json:"tokenOverrides,omitempty"`
+}
+
+// IOStreams carries the streams used for prompts, progress output and hook output.
+// Nil fields fall back to the process's standard streams.
+type IOStreams struct {
+	In     io.Reader
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+func (s IOStreams) withDefaults() IOStreams {
+	if s.In == nil {
+		s.In = os.Stdin
+	}
+	// Share one buffer between variable prompts and later per-file prompts
+	s.In = lineReader(s.In)
+	if s.Out == nil {
+		s.Out = os.Stdout
+	}
+	if s.ErrOut == nil {
+		s.ErrOut = os.Stderr
+	}
+	return s
+}
+
+// ValueSources supplies variable values without prompting. Precedence is
+// Set, then ValuesFile, then SCAFFO_<NAME> environment variables, then defaults.
+type ValueSources struct {
+	Set            map[string]string
+	ValuesFile     string
+	NonInteractive bool
+}
+
+// RunOptions configures a Run invocation. With DryRun set nothing is written
+// and the plan is printed in PlanFormat ("text" or "json") instead. DiffGlob
+// and DiffLimit select templated files to show as unified diffs against their
+// source; either one implies DryRun. Overwrite selects how existing output
+// files are handled (force, skip-existing, backup or interactive); when empty
+// an existing output directory is an error. Strict makes unresolved tokens in
+// the output an error instead of a warning. UseGitignore also excludes what
+// the source tree's .gitignore files exclude, as Config.UseGitignore does,
+// and Verbose reports what excluded each skipped path. Profile selects one of
+// the config's profiles.
+type RunOptions struct {
+	ConfigPath   string
+	SourceRoot   string
+	OutPath      string
+	CopyConfig   bool
+	Values       ValueSources
+	DryRun       bool
+	PlanFormat   string
+	DiffGlob     string
+	DiffLimit    int
+	Overwrite    string
+	Strict       bool
+	UseGitignore bool
+	Verbose      bool
+	Profile      string
+	Streams      IOStreams
+}
+
+// BuildOptions configures a BuildTemplate invocation. UseGitignore and
+// Verbose behave as in RunOptions.
+type BuildOptions struct {
+	ConfigPath   string
+	SourceRoot   string
+	OutputDir    string
+	UseGitignore bool
+	Verbose      bool
+	Streams      IOStreams
+}
+
+// GenerateOptions configures a Generate invocation. ConfigPath optionally
+// overrides the variables and hooks recorded in the template metadata;
+// Overwrite and Strict behave as in RunOptions.
+type GenerateOptions struct {
+	TemplateRoot string
+	OutPath      string
+	CopyConfig   bool
+	ConfigPath   string
+	Values       ValueSources
+	Overwrite    string
+	Strict       bool
+	Streams      IOStreams
+}
+
+// Result summarises what a scaffolding run produced. Paths are slash-separated
+// and relative to OutPath (created files) or the source root (skipped paths).
+// Overwritten, SkippedExisting and BackedUp list output files that already
+// existed. SkippedBy names what excluded each skipped path (see
+// Plan.SkippedBy). Unresolved lists tokens left in the output. Plan is only
+// set for dry runs.
+type Result struct {
+	OutPath             string
+	FilesCreated        []string
+	StaticCopied        []string
+	ReplacementsApplied int
+	Skipped             []string
+	SkippedBy           map[string]string
+	Overwritten         []string
+	SkippedExisting     []string
+	BackedUp            []string
+	Unresolved          []UnresolvedToken
+	Plan                *Plan
+}
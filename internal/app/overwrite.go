@@ -0,0 +1,151 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Overwrite strategies for files that already exist in the output directory.
+const (
+	OverwriteForce       = "force"
+	OverwriteSkip        = "skip-existing"
+	OverwriteBackup      = "backup"
+	OverwriteInteractive = "interactive"
+)
+
+const backupSuffix = ".bak"
+
+func checkOverwriteStrategy(strategy string) error {
+	switch strategy {
+	case "", OverwriteForce, OverwriteSkip, OverwriteBackup, OverwriteInteractive:
+		return nil
+	}
+	return fmt.Errorf("unknown overwrite strategy %q (want force, skip-existing, backup or interactive)", strategy)
+}
+
+// conflictPolicy decides what happens to output files that already exist.
+// A nil policy writes over them.
+type conflictPolicy struct {
+	strategy string
+	in       *bufio.Reader
+	out      io.Writer
+}
+
+func newConflictPolicy(strategy string, streams IOStreams) *conflictPolicy {
+	if strategy == "" {
+		return nil
+	}
+	return &conflictPolicy{strategy: strategy, in: lineReader(streams.In), out: streams.Out}
+}
+
+// prepare is called before writing targetPath (rel is its output-relative
+// path) and reports whether the file should be written.
+func (p *conflictPolicy) prepare(ctx context.Context, targetPath, rel string, res *Result) (bool, error) {
+	if p == nil {
+		return true, nil
+	}
+	info, err := os.Lstat(targetPath)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if info.IsDir() {
+		return false, fmt.Errorf("%s exists and is a directory", rel)
+	}
+
+	strategy := p.strategy
+	if strategy == OverwriteInteractive {
+		if strategy, err = p.ask(ctx, rel); err != nil {
+			return false, err
+		}
+	}
+
+	switch strategy {
+	case OverwriteSkip:
+		res.SkippedExisting = append(res.SkippedExisting, rel)
+		return false, nil
+	case OverwriteBackup:
+		backup, err := freeBackupPath(targetPath)
+		if err != nil {
+			return false, err
+		}
+		if err := os.Rename(targetPath, backup); err != nil {
+			return false, err
+		}
+		res.BackedUp = append(res.BackedUp, rel)
+		return true, nil
+	default:
+		res.Overwritten = append(res.Overwritten, rel)
+		return true, nil
+	}
+}
+
+// freeBackupPath returns the first of path.bak, path.bak.1, path.bak.2, ...
+// that does not exist yet, so earlier backups are never replaced.
+func freeBackupPath(path string) (string, error) {
+	backup := path + backupSuffix
+	for n := 1; ; n++ {
+		_, err := os.Lstat(backup)
+		if os.IsNotExist(err) {
+			return backup, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		backup = fmt.Sprintf("%s%s.%d", path, backupSuffix, n)
+	}
+}
+
+// ask prompts for a single conflicting file. Answering "all" or "none"
+// applies force or skip-existing to every remaining conflict.
+func (p *conflictPolicy) ask(ctx context.Context, rel string) (string, error) {
+	for {
+		fmt.Fprintf(p.out, "%s already exists. Overwrite? [y]es/[n]o/[b]ackup/[a]ll/n[o]ne: ", rel)
+		text, err := readLine(ctx, p.in)
+		if err != nil && text == "" {
+			return "", fmt.Errorf("no answer for existing file %s: %w", rel, err)
+		}
+		switch strings.ToLower(strings.TrimSpace(text)) {
+		case "y", "yes":
+			return OverwriteForce, nil
+		case "n", "no":
+			return OverwriteSkip, nil
+		case "b", "backup":
+			return OverwriteBackup, nil
+		case "a", "all":
+			p.strategy = OverwriteForce
+			return OverwriteForce, nil
+		case "o", "none":
+			p.strategy = OverwriteSkip
+			return OverwriteSkip, nil
+		}
+		fmt.Fprintln(p.out, "Please answer y, n, b, a or o.")
+	}
+}
+
+// writeConflictSummary prints what happened to pre-existing output files.
+func writeConflictSummary(w io.Writer, res *Result) {
+	if len(res.Overwritten)+len(res.SkippedExisting)+len(res.BackedUp) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Existing files: %d overwritten, %d skipped, %d backed up (%s)\n",
+		len(res.Overwritten), len(res.SkippedExisting), len(res.BackedUp), backupSuffix)
+	for _, group := range []struct {
+		label string
+		paths []string
+	}{
+		{"overwritten", res.Overwritten},
+		{"skipped", res.SkippedExisting},
+		{"backed up", res.BackedUp},
+	} {
+		for _, path := range group.paths {
+			fmt.Fprintf(w, "  %-11s %s\n", group.label, path)
+		}
+	}
+}
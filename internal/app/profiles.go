@@ -0,0 +1,142 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile adjusts the config for one flavour of project (e.g. web-api, spa
+// or cli) when selected with --profile. Its ignore and static lists extend
+// the config's, and a pattern starting with "!" removes that pattern
+// instead. Variables override those of the same name, replacements and
+// rename rules override those with the same Find or From, and hooks run
+// after the config's hooks for the same phase.
+type Profile struct {
+	IgnoreFolders []string            `json:"ignoreFolders,omitempty"`
+	IgnoreFiles   []string            `json:"ignoreFiles,omitempty"`
+	StaticFiles   []string            `json:"staticFiles,omitempty"`
+	Variables     map[string]Variable `json:"variables,omitempty"`
+	Replacements  []Replacement       `json:"replacements,omitempty"`
+	RenameRules   []RenameRule        `json:"renameRules,omitempty"`
+	Hooks         map[string][]Hook   `json:"hooks,omitempty"`
+}
+
+// ProfileNames lists the config's profiles in alphabetical order.
+func (cfg *Config) ProfileNames() []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile merges the named profile into cfg; an empty name is a no-op.
+func (cfg *Config) applyProfile(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		if len(cfg.Profiles) == 0 {
+			return fmt.Errorf("unknown profile %q: the config defines no profiles", name)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(cfg.ProfileNames(), ", "))
+	}
+
+	cfg.IgnoreFolders = mergeProfilePatterns(cfg.IgnoreFolders, p.IgnoreFolders)
+	cfg.IgnoreFiles = mergeProfilePatterns(cfg.IgnoreFiles, p.IgnoreFiles)
+	cfg.StaticFiles = mergeProfilePatterns(cfg.StaticFiles, p.StaticFiles)
+
+	if len(p.Variables) > 0 {
+		vars := make(map[string]Variable, len(cfg.Variables)+len(p.Variables))
+		for k, v := range cfg.Variables {
+			vars[k] = v
+		}
+		for k, v := range p.Variables {
+			vars[k] = v
+		}
+		cfg.Variables = vars
+	}
+
+	replacements := append([]Replacement{}, cfg.Replacements...)
+next:
+	for _, repl := range p.Replacements {
+		for i := range replacements {
+			if replacements[i].Find == repl.Find {
+				replacements[i] = repl
+				continue next
+			}
+		}
+		replacements = append(replacements, repl)
+	}
+	cfg.Replacements = replacements
+
+	rules := append([]RenameRule{}, cfg.RenameRules...)
+nextRule:
+	for _, rule := range p.RenameRules {
+		for i := range rules {
+			if rules[i].From == rule.From {
+				rules[i] = rule
+				continue nextRule
+			}
+		}
+		rules = append(rules, rule)
+	}
+	cfg.RenameRules = rules
+
+	if len(p.Hooks) > 0 {
+		hooks := make(map[string][]Hook, len(cfg.Hooks)+len(p.Hooks))
+		for phase, list := range cfg.Hooks {
+			hooks[phase] = list
+		}
+		for phase, list := range p.Hooks {
+			hooks[phase] = append(append([]Hook{}, hooks[phase]...), list...)
+		}
+		cfg.Hooks = hooks
+	}
+	return nil
+}
+
+// mergeProfilePatterns adds a profile's patterns to base with mergePatterns;
+// patterns starting with "!" remove the rest of the pattern from base.
+func mergeProfilePatterns(base, profile []string) []string {
+	var add []string
+	remove := map[string]bool{}
+	for _, pat := range profile {
+		pat = strings.TrimSpace(pat)
+		if rest, ok := strings.CutPrefix(pat, "!"); ok {
+			remove[strings.TrimSpace(rest)] = true
+			continue
+		}
+		add = append(add, pat)
+	}
+	merged := mergePatterns(base, add)
+	if len(remove) == 0 {
+		return merged
+	}
+	kept := merged[:0]
+	for _, pat := range merged {
+		if !remove[pat] {
+			kept = append(kept, pat)
+		}
+	}
+	return kept
+}
+
+// checkProfiles compiles the profiles' replacements like compileReplacements.
+func checkProfiles(profiles map[string]Profile) error {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := compileReplacements(profiles[name].Replacements); err != nil {
+			return fmt.Errorf("profiles[%q]: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// compileReplacements compiles the pattern of every regex replacement and
+// checks that ReplaceWith only refers to capture groups the pattern has.
+func compileReplacements(replacements []Replacement) error {
+	for i := range replacements {
+		repl := &replacements[i]
+		if !repl.Regex || repl.Find == "" {
+			continue
+		}
+		re, err := regexp.Compile(repl.Find)
+		if err != nil {
+			return fmt.Errorf("replacements[%d]: invalid regex %q: %w", i, repl.Find, err)
+		}
+		if err := checkGroupRefs(re, repl.ReplaceWith); err != nil {
+			return fmt.Errorf("replacements[%d]: %w", i, err)
+		}
+		repl.re = re
+	}
+	return nil
+}
+
+// checkGroupRefs reports $n and ${name} references in template that name no
+// capture group of re. Go reads "$1x" as the group "1x", so that mistake is
+// caught here too.
+func checkGroupRefs(re *regexp.Regexp, template string) error {
+	for i := 0; i < len(template); i++ {
+		if template[i] != '$' {
+			continue
+		}
+		rest := template[i+1:]
+		if strings.HasPrefix(rest, "$") {
+			i++
+			continue
+		}
+		var name string
+		if strings.HasPrefix(rest, "{") {
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				continue
+			}
+			name = rest[1:end]
+		} else {
+			end := 0
+			for end < len(rest) && isGroupNameByte(rest[end]) {
+				end++
+			}
+			name = rest[:end]
+		}
+		if name == "" || hasGroup(re, name) {
+			continue
+		}
+		return fmt.Errorf("replaceWith refers to unknown capture group $%s in %q (write ${1} when a group number is followed by a letter)", name, template)
+	}
+	return nil
+}
+
+func isGroupNameByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func hasGroup(re *regexp.Regexp, name string) bool {
+	if n, err := strconv.Atoi(name); err == nil {
+		return n >= 0 && n <= re.NumSubexp()
+	}
+	return re.SubexpIndex(name) >= 0
+}
+
+// apply replaces every occurrence of the replacement's Find in content with
+// with, expanding capture groups for regex replacements, and reports how
+// many occurrences there were.
+func (r Replacement) apply(content, with string) (string, int) {
+	if r.re == nil {
+		n := strings.Count(content, r.Find)
+		if n == 0 {
+			return content, 0
+		}
+		return strings.ReplaceAll(content, r.Find, with), n
+	}
+	n := len(r.re.FindAllStringIndex(content, -1))
+	if n == 0 {
+		return content, 0
+	}
+	return r.re.ReplaceAllString(content, with), n
+}
+
+// inScope reports whether rel matches one of include, or include is empty,
+// and none of exclude. Like ignoreFiles, a pattern may match the whole path
+// or its base name, so "*.csproj" applies at any depth.
+func inScope(rel string, include, exclude []string) bool {
+	matches := func(patterns []string) bool {
+		base := path.Base(rel)
+		for _, pat := range patterns {
+			if matchGlob(rel, pat) || matchGlob(base, pat) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(include) > 0 && !matches(include) {
+		return false
+	}
+	return !matches(exclude)
+}
+
+// renameInScope applies a scoped rename rule to current, the path rel after
+// earlier rules, one segment at a time: a segment is renamed when the source
+// path up to it is in scope, so a directory and its contents stay in step.
+func renameInScope(rel, current string, rule RenameRule) string {
+	srcParts := strings.Split(rel, "/")
+	parts := strings.Split(current, "/")
+	if len(parts) != len(srcParts) {
+		// An earlier rule added or removed separators
+		if inScope(rel, rule.Include, rule.Exclude) {
+			return strings.ReplaceAll(current, rule.From, rule.To)
+		}
+		return current
+	}
+	for i := range parts {
+		if inScope(strings.Join(srcParts[:i+1], "/"), rule.Include, rule.Exclude) {
+			parts[i] = strings.ReplaceAll(parts[i], rule.From, rule.To)
+		}
+	}
+	return strings.Join(parts, "/")
+}
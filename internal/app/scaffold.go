@@ -0,0 +1,339 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Operation kinds in a Plan.
+const (
+	OpDir       = "dir"
+	OpTemplated = "templated"
+	OpStatic    = "static"
+)
+
+// Plan lists what scaffolding a source tree into OutPath does, in walk order.
+// Paths are slash-separated and relative to SourceRoot and OutPath; skipped
+// directories carry a trailing slash. SkippedBy names, for each skipped path,
+// the ignore list, ignore file rule or condition that excluded it. Unresolved
+// lists tokens left in destination paths and, once described, in file
+// contents.
+type Plan struct {
+	SourceRoot string             `json:"sourceRoot"`
+	OutPath    string             `json:"outPath"`
+	Operations []PlannedOperation `json:"operations"`
+	Skipped    []string           `json:"skipped"`
+	SkippedBy  map[string]string  `json:"skippedBy,omitempty"`
+	Unresolved []UnresolvedToken  `json:"unresolved,omitempty"`
+}
+
+// PlannedOperation describes how one source path is written to the output.
+// Replacements and Diff are only filled in when the plan is described (see describePlan).
+type PlannedOperation struct {
+	Source       string           `json:"source"`
+	Destination  string           `json:"destination"`
+	Kind         string           `json:"kind"`
+	Replacements []ReplacementHit `json:"replacements,omitempty"`
+	Diff         string           `json:"diff,omitempty"`
+}
+
+// ReplacementHit records how often a configured replacement fired in a file.
+type ReplacementHit struct {
+	Find        string `json:"find"`
+	ReplaceWith string `json:"replaceWith"`
+	Count       int    `json:"count"`
+}
+
+// scaffoldProject plans the walk of sourceRoot for outPath and writes the
+// result below writeRoot, normally a staging directory for outPath.
+func scaffoldProject(ctx context.Context, cfg *Config, sourceRoot, outPath, writeRoot string, values map[string]string, res *Result) error {
+	plan, err := planProject(ctx, cfg, sourceRoot, outPath, values)
+	if err != nil {
+		return err
+	}
+	res.Skipped = append(res.Skipped, plan.Skipped...)
+	if res.SkippedBy == nil {
+		res.SkippedBy = map[string]string{}
+	}
+	for path, source := range plan.SkippedBy {
+		res.SkippedBy[path] = source
+	}
+	res.Unresolved = append(res.Unresolved, plan.Unresolved...)
+	return executePlan(ctx, cfg, plan, writeRoot, values, res)
+}
+
+// writeSkipReport lists the skipped paths with what excluded each of them.
+func writeSkipReport(w io.Writer, skipped []string, skippedBy map[string]string) {
+	for _, path := range skipped {
+		fmt.Fprintf(w, "Skipped %s (%s)\n", path, skippedBy[path])
+	}
+}
+
+// isTemplateBuild reports whether values belong to a template build, which
+// passes nil values. A template build has no answers yet, so it keeps every
+// conditional path and marker block for generation to decide.
+func isTemplateBuild(values map[string]string) bool {
+	return values == nil
+}
+
+// planProject walks the source tree and decides, without writing anything,
+// which paths are skipped and where every other path ends up.
+func planProject(ctx context.Context, cfg *Config, sourceRoot, outPath string, values map[string]string) (*Plan, error) {
+	ignores, err := newIgnoreTree(sourceRoot, cfg.UseGitignore)
+	if err != nil {
+		return nil, err
+	}
+	conditions, err := compileConditionalPaths(cfg.ConditionalPaths)
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{SourceRoot: sourceRoot, OutPath: outPath, SkippedBy: map[string]string{}}
+
+	err = filepath.WalkDir(sourceRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if path == sourceRoot {
+			return nil
+		}
+
+		// Skip the output directory and its staging directories if they're
+		// inside sourceRoot (to avoid infinite recursion)
+		if path == outPath || strings.HasPrefix(path, outPath+string(filepath.Separator)) || isStagingPath(path, outPath) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(sourceRoot, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		skippedBy := configIgnoreSource(rel, d.IsDir(), cfg.IgnoreFolders, cfg.IgnoreFiles)
+		if skippedBy == "" {
+			if rule, ignored := ignores.Match(rel, d.IsDir()); ignored {
+				skippedBy = rule.String()
+			}
+		}
+		if len(cfg.Include) > 0 {
+			// Included paths win over ignores, so ignored directories are
+			// walked when included paths may lie below them
+			switch {
+			case d.IsDir() && mayContainInclude(rel, cfg.Include):
+				skippedBy = ""
+			case !d.IsDir() && MatchInclude(rel, cfg.Include):
+				skippedBy = ""
+			case skippedBy == "":
+				skippedBy = "not included"
+			}
+		}
+		if skippedBy == "" && !isTemplateBuild(values) {
+			included, err := conditions.include(rel, values)
+			if err != nil {
+				return err
+			}
+			if !included {
+				skippedBy = "conditionalPaths"
+			}
+		}
+		if skippedBy != "" {
+			skipped := rel
+			if d.IsDir() {
+				skipped += "/"
+			}
+			plan.Skipped = append(plan.Skipped, skipped)
+			plan.SkippedBy[skipped] = skippedBy
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			// Nested ignore files apply to the directory they are in
+			if err := ignores.enter(rel); err != nil {
+				return err
+			}
+			if len(cfg.Include) > 0 {
+				// Only the parents of included files are created
+				return nil
+			}
+		}
+
+		// 1. Apply RenameRules
+		renamedRel := applyRenameRules(rel, localRenameRules(cfg, rel))
+
+		// 2. Apply Token Replacement to path
+		resolvedRel, unresolved, err := renderTokens(cfg, rel, renamedRel, values)
+		if err != nil {
+			return fmt.Errorf("rendering path: %w", err)
+		}
+		for _, tok := range unresolved {
+			// Tokens in parent directories were reported with the directory
+			if strings.Contains(resolvedRel[strings.LastIndex(resolvedRel, "/")+1:], tok.Token) {
+				plan.Unresolved = append(plan.Unresolved, UnresolvedToken{Path: resolvedRel, Token: tok.Token})
+			}
+		}
+
+		op := PlannedOperation{Source: rel, Destination: resolvedRel, Kind: OpTemplated}
+		switch {
+		case d.IsDir():
+			op.Kind = OpDir
+		case matchesPatternList(rel, cfg.StaticFiles):
+			op.Kind = OpStatic
+		}
+		plan.Operations = append(plan.Operations, op)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// conditionalPath is a compiled Config.ConditionalPaths entry.
+type conditionalPath struct {
+	glob string
+	cond *condition
+}
+
+type conditionalPaths []conditionalPath
+
+// compileConditionalPaths parses every condition, ordered by glob so that
+// evaluation errors are reported deterministically.
+func compileConditionalPaths(paths map[string]string) (conditionalPaths, error) {
+	globs := make([]string, 0, len(paths))
+	for glob := range paths {
+		globs = append(globs, glob)
+	}
+	sort.Strings(globs)
+	var compiled conditionalPaths
+	for _, glob := range globs {
+		cond, err := parseCondition(paths[glob])
+		if err != nil {
+			return nil, fmt.Errorf("conditionalPaths[%q]: %w", glob, err)
+		}
+		compiled = append(compiled, conditionalPath{glob: glob, cond: cond})
+	}
+	return compiled, nil
+}
+
+// include reports whether rel passes the conditions of every glob it matches.
+func (c conditionalPaths) include(rel string, values map[string]string) (bool, error) {
+	for _, cp := range c {
+		if !matchGlob(rel, cp.glob) {
+			continue
+		}
+		ok, err := cp.cond.eval(values)
+		if err != nil {
+			return false, fmt.Errorf("conditionalPaths[%q]: %w", cp.glob, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// executePlan writes every planned operation below writeRoot.
+func executePlan(ctx context.Context, cfg *Config, plan *Plan, writeRoot string, values map[string]string, res *Result) error {
+	if err := os.MkdirAll(writeRoot, 0o755); err != nil {
+		return err
+	}
+
+	for _, op := range plan.Operations {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		srcPath := filepath.Join(plan.SourceRoot, filepath.FromSlash(op.Source))
+		targetPath := filepath.Join(writeRoot, filepath.FromSlash(op.Destination))
+
+		if op.Kind == OpDir {
+			if err := os.MkdirAll(targetPath, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Ensure parent dir exists
+		if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+			return err
+		}
+
+		info, err := os.Stat(srcPath)
+		if err != nil {
+			return err
+		}
+
+		if op.Kind == OpStatic {
+			if err := copyFile(srcPath, targetPath, info.Mode()); err != nil {
+				return err
+			}
+			res.StaticCopied = append(res.StaticCopied, op.Destination)
+			continue
+		}
+
+		data, err := os.ReadFile(srcPath)
+		if err != nil {
+			return err
+		}
+		content, hits, unresolved, err := renderContent(cfg, op.Source, string(data), values)
+		if err != nil {
+			return err
+		}
+		for _, tok := range unresolved {
+			tok.Path = op.Destination
+			res.Unresolved = append(res.Unresolved, tok)
+		}
+		if err := os.WriteFile(targetPath, []byte(content), info.Mode()); err != nil {
+			return err
+		}
+		for _, hit := range hits {
+			res.ReplacementsApplied += hit.Count
+		}
+		res.FilesCreated = append(res.FilesCreated, op.Destination)
+	}
+	return nil
+}
+
+// renderContent resolves marker blocks, then applies Config.Replacements and
+// variable tokens to the content of the templated file at source, reporting
+// which replacements fired and which tokens were left unresolved.
+func renderContent(cfg *Config, source, content string, values map[string]string) (string, []ReplacementHit, []UnresolvedToken, error) {
+	var hits []ReplacementHit
+
+	if !isTemplateBuild(values) {
+		var err error
+		if content, err = processMarkers(source, content, values); err != nil {
+			return "", nil, nil, err
+		}
+	}
+
+	// Apply Config.Replacements first (e.g. "Banker" -> "{{PROJECT_NAME}}")
+	for _, repl := range cfg.Replacements {
+		if repl.Find == "" || !inScope(source, repl.Include, repl.Exclude) {
+			continue
+		}
+		var n int
+		if content, n = repl.apply(content, localTokens(cfg, source, repl.ReplaceWith)); n > 0 {
+			hits = append(hits, ReplacementHit{Find: repl.Find, ReplaceWith: repl.ReplaceWith, Count: n})
+		}
+	}
+
+	content, unresolved, err := renderTokens(cfg, source, content, values)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return content, hits, unresolved, nil
+}
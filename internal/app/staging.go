@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const stagingInfix = ".scaffo-staging-"
+
+// stagingDir is a sibling of the output directory that a run writes into.
+// The result is only moved into place by commit; cleanup removes whatever is
+// left, so failures and cancellations never leave a half-written project.
+type stagingDir struct {
+	path   string
+	target string
+}
+
+func newStagingDir(target string) (*stagingDir, error) {
+	parent := filepath.Dir(target)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return nil, err
+	}
+	path, err := os.MkdirTemp(parent, "."+filepath.Base(target)+stagingInfix+"*")
+	if err != nil {
+		return nil, err
+	}
+	return &stagingDir{path: path, target: target}, nil
+}
+
+// isStagingPath reports whether path is a staging directory for target, so
+// that walks of a source tree containing the output can skip it.
+func isStagingPath(path, target string) bool {
+	return filepath.Dir(path) == filepath.Dir(target) &&
+		strings.HasPrefix(filepath.Base(path), "."+filepath.Base(target)+stagingInfix)
+}
+
+// commit moves the staged tree to the target. A missing target is replaced
+// by a single rename; an existing one is merged file by file, consulting
+// conflicts for every file that is already present.
+func (s *stagingDir) commit(ctx context.Context, conflicts *conflictPolicy, res *Result) error {
+	if _, err := os.Lstat(s.target); os.IsNotExist(err) {
+		return os.Rename(s.path, s.target)
+	}
+
+	return filepath.WalkDir(s.path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.path, path)
+		if err != nil {
+			return err
+		}
+		targetPath := filepath.Join(s.target, rel)
+		if d.IsDir() {
+			return os.MkdirAll(targetPath, 0o755)
+		}
+		write, err := conflicts.prepare(ctx, targetPath, filepath.ToSlash(rel), res)
+		if err != nil || !write {
+			return err
+		}
+		return os.Rename(path, targetPath)
+	})
+}
+
+// cleanup removes the staging directory; it is a no-op after a rename commit.
+func (s *stagingDir) cleanup() {
+	os.RemoveAll(s.path)
+}
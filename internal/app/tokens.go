@@ -0,0 +1,204 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Token blocks whose contents are copied verbatim, e.g. {{raw}}...{{endraw}}.
+const (
+	rawToken    = "raw"
+	endRawToken = "endraw"
+)
+
+// tokenError reports a malformed token on a 1-based line of the input.
+type tokenError struct {
+	line int
+	msg  string
+}
+
+func (e *tokenError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.msg)
+}
+
+// UnresolvedToken is a token left in the output because it names no
+// variable. Line is 0 for tokens in path names.
+type UnresolvedToken struct {
+	Path  string `json:"path"`
+	Line  int    `json:"line,omitempty"`
+	Token string `json:"token"`
+}
+
+func (t UnresolvedToken) String() string {
+	if t.Line == 0 {
+		return fmt.Sprintf("%s: %s", t.Path, t.Token)
+	}
+	return fmt.Sprintf("%s:%d: %s", t.Path, t.Line, t.Token)
+}
+
+// reportUnresolved warns about every unresolved token; in strict mode they
+// are an error.
+func reportUnresolved(w io.Writer, unresolved []UnresolvedToken, strict bool) error {
+	if len(unresolved) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "Warning: %d unresolved token(s):\n", len(unresolved))
+	for _, tok := range unresolved {
+		fmt.Fprintf(w, "  %s\n", tok)
+	}
+	if strict {
+		return fmt.Errorf("%d unresolved token(s) in output (escape literal delimiters or use a raw block)", len(unresolved))
+	}
+	return nil
+}
+
+// replaceTokens is expandTokens for text whose unresolved tokens need no report.
+func replaceTokens(input string, values map[string]string, start, end string) (string, error) {
+	out, _, err := expandTokens(input, values, start, end)
+	return out, err
+}
+
+// expandTokens resolves the tokens in input and lists, with their lines, the
+// tokens that name no value; those are left untouched. A token is start, a
+// name and end, optionally followed by filters that name transforms:
+// {{NAME}}, {{NAME|kebab}} or {{ NAME | snake | upper }}. A doubled start
+// delimiter ({{{{) is written as a literal start delimiter, and text between
+// {{raw}} and {{endraw}} is copied verbatim. Malformed input is a *tokenError.
+// With nil values, as when building a template skeleton, input is returned
+// unchanged so that escapes survive until generation.
+func expandTokens(input string, values map[string]string, start, end string) (string, []UnresolvedToken, error) {
+	if values == nil || !strings.Contains(input, start) {
+		return input, nil, nil
+	}
+	var (
+		b          strings.Builder
+		unresolved []UnresolvedToken
+	)
+	lineAt := func(offset int) int { return strings.Count(input[:offset], "\n") + 1 }
+	i := 0
+	for {
+		from := strings.Index(input[i:], start)
+		if from < 0 {
+			break
+		}
+		from += i
+		if strings.HasPrefix(input[from+len(start):], start) {
+			b.WriteString(input[i:from] + start)
+			i = from + 2*len(start)
+			continue
+		}
+		inner, next, ok := nextToken(input, from, start, end)
+		if !ok {
+			break
+		}
+		if strings.Contains(inner, start) {
+			// Only the innermost start delimiter can begin this token
+			b.WriteString(input[i : from+len(start)])
+			i = from + len(start)
+			continue
+		}
+		b.WriteString(input[i:from])
+		i = next
+
+		parts := strings.Split(inner, "|")
+		name := strings.TrimSpace(parts[0])
+		if name == rawToken && len(parts) == 1 {
+			rawEnd, after := findEndRaw(input, i, start, end)
+			if rawEnd < 0 {
+				return "", nil, &tokenError{line: lineAt(from), msg: fmt.Sprintf("%s%s%s without %s%s%s", start, rawToken, end, start, endRawToken, end)}
+			}
+			b.WriteString(input[i:rawEnd])
+			i = after
+			continue
+		}
+		value, ok := values[name]
+		if !ok {
+			unresolved = append(unresolved, UnresolvedToken{Line: lineAt(from), Token: input[from:i]})
+			b.WriteString(input[from:i])
+			continue
+		}
+		for _, filter := range parts[1:] {
+			filter = strings.TrimSpace(filter)
+			fn, ok := transforms[strings.ToLower(filter)]
+			if !ok {
+				return "", nil, &tokenError{line: lineAt(from), msg: fmt.Sprintf("unknown filter %q in %s", filter, input[from:i])}
+			}
+			value = fn(value)
+		}
+		b.WriteString(value)
+	}
+	b.WriteString(input[i:])
+	return b.String(), unresolved, nil
+}
+
+// localTokens rewrites the tokens in s that use the configured delimiters,
+// such as those in a replacement's replaceWith, to the delimiters used for
+// the source path rel.
+func localTokens(cfg *Config, rel, s string) string {
+	start, end := defaultTokenDelims(cfg.Token)
+	localStart, localEnd := tokenDelimsFor(cfg, rel)
+	if start == localStart && end == localEnd {
+		return s
+	}
+	var b strings.Builder
+	for {
+		from := strings.Index(s, start)
+		if from < 0 {
+			break
+		}
+		inner, next, ok := nextToken(s, from, start, end)
+		if !ok {
+			break
+		}
+		b.WriteString(s[:from] + localStart + inner + localEnd)
+		s = s[next:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// localRenameRules returns cfg.RenameRules with their targets rewritten by
+// localTokens for rel.
+func localRenameRules(cfg *Config, rel string) []RenameRule {
+	if len(cfg.TokenOverrides) == 0 {
+		return cfg.RenameRules
+	}
+	rules := make([]RenameRule, len(cfg.RenameRules))
+	for i, rule := range cfg.RenameRules {
+		rule.To = localTokens(cfg, rel, rule.To)
+		rules[i] = rule
+	}
+	return rules
+}
+
+// nextToken returns the text between the start delimiter at from and the
+// following end delimiter, and the offset just past that end delimiter.
+func nextToken(input string, from int, start, end string) (string, int, bool) {
+	body := from + len(start)
+	to := strings.Index(input[body:], end)
+	if to < 0 {
+		return "", 0, false
+	}
+	return input[body : body+to], body + to + len(end), true
+}
+
+// findEndRaw locates the {{endraw}} token at or after offset i, returning
+// where it starts and ends, or -1 when there is none.
+func findEndRaw(input string, i int, start, end string) (int, int) {
+	for {
+		from := strings.Index(input[i:], start)
+		if from < 0 {
+			return -1, 0
+		}
+		from += i
+		inner, next, ok := nextToken(input, from, start, end)
+		if !ok {
+			return -1, 0
+		}
+		if strings.TrimSpace(inner) == endRawToken {
+			return from, next
+		}
+		i = from + len(start)
+	}
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"strings"
+	"unicode"
+)
+
+// transforms maps the names usable in Variable.Transform to their functions.
+// The case transforms split their input into words like generateVariations.
+var transforms = map[string]func(string) string{
+	"identity":   func(s string) string { return s },
+	"slug-kebab": func(s string) string { return slugify(s, '-') },
+	"slug-snake": func(s string) string { return slugify(s, '_') },
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"title":      titleCase,
+	"ascii":      transliterate,
+	"pascal":     wordsTransform(toPascalCase),
+	"camel":      wordsTransform(toCamelCase),
+	"snake":      wordsTransform(toSnakeCase),
+	"kebab":      wordsTransform(toKebabCase),
+	"screaming":  wordsTransform(toScreamingSnakeCase),
+	"dot":        joinedLower("."),
+	"path":       joinedLower("/"),
+	"flat":       joinedLower(""),
+	"train":      wordsTransform(toTrainCase),
+	"plural":     func(s string) string { return inflectLastWord(s, pluralize) },
+	"singular":   func(s string) string { return inflectLastWord(s, singularize) },
+}
+
+// applyTransform runs input through a transform pipeline; unknown names
+// leave the value unchanged.
+func applyTransform(input string, transform Transform) string {
+	for _, step := range transform.steps() {
+		if fn, ok := transforms[strings.ToLower(step)]; ok {
+			input = fn(input)
+		}
+	}
+	return input
+}
+
+func wordsTransform(fn func([]string) string) func(string) string {
+	return func(s string) string { return fn(splitIntoWords(s)) }
+}
+
+func joinedLower(sep string) func(string) string {
+	return func(s string) string {
+		words := splitIntoWords(s)
+		for i, w := range words {
+			words[i] = strings.ToLower(w)
+		}
+		return strings.Join(words, sep)
+	}
+}
+
+func toTrainCase(words []string) string {
+	parts := make([]string, len(words))
+	for i, w := range words {
+		parts[i] = toPascalCase([]string{w})
+	}
+	return strings.Join(parts, "-")
+}
+
+// transliterations maps accented and special Latin letters to ASCII.
+var transliterations = map[rune]string{
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Ā': "A", 'Ă': "A", 'Ą': "A",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'Æ': "AE", 'æ': "ae",
+	'Ç': "C", 'Ć': "C", 'Ĉ': "C", 'Ċ': "C", 'Č': "C",
+	'ç': "c", 'ć': "c", 'ĉ': "c", 'ċ': "c", 'č': "c",
+	'Ď': "D", 'Đ': "D", 'Ð': "D", 'ď': "d", 'đ': "d", 'ð': "d",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ē': "E", 'Ĕ': "E", 'Ė': "E", 'Ę': "E", 'Ě': "E",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ĕ': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'Ĝ': "G", 'Ğ': "G", 'Ġ': "G", 'Ģ': "G", 'ĝ': "g", 'ğ': "g", 'ġ': "g", 'ģ': "g",
+	'Ĥ': "H", 'Ħ': "H", 'ĥ': "h", 'ħ': "h",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ĩ': "I", 'Ī': "I", 'Ĭ': "I", 'Į': "I", 'İ': "I",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ĩ': "i", 'ī': "i", 'ĭ': "i", 'į': "i", 'ı': "i",
+	'Ĳ': "IJ", 'ĳ': "ij", 'Ĵ': "J", 'ĵ': "j", 'Ķ': "K", 'ķ': "k",
+	'Ĺ': "L", 'Ļ': "L", 'Ľ': "L", 'Ŀ': "L", 'Ł': "L", 'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ŀ': "l", 'ł': "l",
+	'Ñ': "N", 'Ń': "N", 'Ņ': "N", 'Ň': "N", 'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O", 'Ō': "O", 'Ŏ': "O", 'Ő': "O",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ŏ': "o", 'ő': "o",
+	'Œ': "OE", 'œ': "oe",
+	'Ŕ': "R", 'Ŗ': "R", 'Ř': "R", 'ŕ': "r", 'ŗ': "r", 'ř': "r",
+	'Ś': "S", 'Ŝ': "S", 'Ş': "S", 'Š': "S", 'ś': "s", 'ŝ': "s", 'ş': "s", 'š': "s", 'ß': "ss",
+	'Ţ': "T", 'Ť': "T", 'Ŧ': "T", 'ţ': "t", 'ť': "t", 'ŧ': "t", 'Þ': "TH", 'þ': "th",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ũ': "U", 'Ū': "U", 'Ŭ': "U", 'Ů': "U", 'Ű': "U", 'Ų': "U",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ũ': "u", 'ū': "u", 'ŭ': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'Ŵ': "W", 'ŵ': "w",
+	'Ý': "Y", 'Ŷ': "Y", 'Ÿ': "Y", 'ý': "y", 'ÿ': "y", 'ŷ': "y",
+	'Ź': "Z", 'Ż': "Z", 'Ž': "Z", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// transliterate replaces accented Latin letters with their ASCII base
+// letters, so "Café Über" becomes "Cafe Uber". Other runes are kept.
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if ascii, ok := transliterations[r]; ok {
+			b.WriteString(ascii)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// irregularPlurals lists lowercase singular -> plural pairs that the suffix
+// rules get wrong; uncountable nouns map to themselves.
+var irregularPlurals = map[string]string{
+	"person": "people", "man": "men", "woman": "women", "child": "children",
+	"mouse": "mice", "goose": "geese", "foot": "feet", "tooth": "teeth", "ox": "oxen",
+	"leaf": "leaves", "life": "lives", "knife": "knives", "wife": "wives",
+	"half": "halves", "wolf": "wolves", "shelf": "shelves", "calf": "calves",
+	"index": "indices", "matrix": "matrices", "vertex": "vertices", "criterion": "criteria",
+	"status": "statuses", "alias": "aliases", "bus": "buses", "virus": "viruses",
+	"sheep": "sheep", "fish": "fish", "series": "series", "species": "species",
+	"news": "news", "data": "data", "metadata": "metadata", "information": "information",
+	"equipment": "equipment", "software": "software", "feedback": "feedback",
+}
+
+var irregularSingulars = func() map[string]string {
+	m := make(map[string]string, len(irregularPlurals))
+	for singular, plural := range irregularPlurals {
+		m[plural] = singular
+	}
+	return m
+}()
+
+// pluralize returns the English plural of a lowercase word.
+func pluralize(word string) string {
+	if plural, ok := irregularPlurals[word]; ok {
+		return plural
+	}
+	switch {
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	default:
+		return word + "s"
+	}
+}
+
+// singularize returns the English singular of a lowercase word.
+func singularize(word string) string {
+	if singular, ok := irregularSingulars[word]; ok {
+		return singular
+	}
+	if _, ok := irregularPlurals[word]; ok {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return word[:len(word)-1]
+	default:
+		return word
+	}
+}
+
+// inflectLastWord applies fn to the last word of s, so that "UserProfile"
+// pluralizes to "UserProfiles" and "order_item" to "order_items". The
+// word's case (lower, Capitalized or UPPER) is kept.
+func inflectLastWord(s string, fn func(string) string) string {
+	runes := []rune(s)
+	end := len(runes)
+	start := end
+	for start > 0 && unicode.IsLetter(runes[start-1]) {
+		start--
+		if start > 0 && unicode.IsUpper(runes[start]) && !unicode.IsUpper(runes[start-1]) {
+			break
+		}
+		// Acronym boundary, as in "HTTPServer"
+		if start > 0 && start+1 < end && unicode.IsUpper(runes[start]) && unicode.IsUpper(runes[start-1]) && unicode.IsLower(runes[start+1]) {
+			break
+		}
+	}
+	if start == end {
+		return s
+	}
+	word := string(runes[start:end])
+	inflected := fn(strings.ToLower(word))
+	switch {
+	case word == strings.ToUpper(word) && len(runes[start:end]) > 1:
+		inflected = strings.ToUpper(inflected)
+	case unicode.IsUpper(runes[start]):
+		inflected = toPascalCase([]string{inflected})
+	}
+	return string(runes[:start]) + inflected
+}
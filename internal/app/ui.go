@@ -13,8 +13,12 @@ type uiState int
 const (
 stateMenu uiState = iota
 stateFolderSelect
+stateProfileSelect
 )
 
+// noProfile is the profile picker entry for running without a profile.
+const noProfile = "(no profile)"
+
 type model struct {
 	state          uiState
 	choices        []string
@@ -24,14 +28,20 @@ type model struct {
 	folders        []string
 	folderCursor   int
 	selectedFolder string
-	err            error
+
+	configPath      string
+	profiles        []string
+	profileCursor   int
+	selectedProfile string
+	err             error
 }
 
-func initialModel() model {
+func initialModel(configPath string) model {
 	return model{
-		state:   stateMenu,
-		choices: []string{"Init", "Run", "Quit"},
-		cursor:  0,
+		state:      stateMenu,
+		choices:    []string{"Init", "Run", "Quit"},
+		cursor:     0,
+		configPath: configPath,
 	}
 }
 
@@ -51,6 +61,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateMenu
 				return m, nil
 			}
+			if m.state == stateProfileSelect {
+				m.state = stateFolderSelect
+				return m, nil
+			}
 			m.selected = "quit"
 			return m, tea.Quit
 		case "up", "k":
@@ -62,6 +76,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.folderCursor > 0 {
 					m.folderCursor--
 				}
+			} else if m.state == stateProfileSelect {
+				if m.profileCursor > 0 {
+					m.profileCursor--
+				}
 			}
 		case "down", "j":
 			if m.state == stateMenu {
@@ -72,6 +90,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.folderCursor < len(m.folders)-1 {
 					m.folderCursor++
 				}
+			} else if m.state == stateProfileSelect {
+				if m.profileCursor < len(m.profiles)-1 {
+					m.profileCursor++
+				}
 			}
 		case "enter":
 			if m.state == stateMenu {
@@ -92,6 +114,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else if m.state == stateFolderSelect {
 				m.selected = "run"
 				m.selectedFolder = m.folders[m.folderCursor]
+				// Offer the config's profiles, if it has any
+				if cfg, err := LoadConfig(m.configPath); err == nil && len(cfg.Profiles) > 0 {
+					m.profiles = append([]string{noProfile}, cfg.ProfileNames()...)
+					m.state = stateProfileSelect
+					m.profileCursor = 0
+					return m, nil
+				}
+				return m, tea.Quit
+			} else if m.state == stateProfileSelect {
+				if profile := m.profiles[m.profileCursor]; profile != noProfile {
+					m.selectedProfile = profile
+				}
 				return m, tea.Quit
 			}
 		}
@@ -124,6 +158,15 @@ func (m model) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, folder)
 		}
+	} else if m.state == stateProfileSelect {
+		s += "Select profile:\n"
+		for i, profile := range m.profiles {
+			cursor := " "
+			if m.profileCursor == i {
+				cursor = ">"
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, profile)
+		}
 	}
 
 	s += "\nUse ↑/↓ to move, Enter to select, q to quit/back."
@@ -145,13 +188,14 @@ func getFolders() ([]string, error) {
 	return folders, nil
 }
 
-// RunUI runs the Bubble Tea UI and returns the selected command and argument (if any)
-func RunUI() (string, string, error) {
-	p := tea.NewProgram(initialModel())
+// RunUI runs the Bubble Tea UI and returns the selected command, argument and
+// profile (if any). Profiles are read from the config at configPath.
+func RunUI(configPath string) (string, string, string, error) {
+	p := tea.NewProgram(initialModel(configPath))
 	m, err := p.Run()
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 	finalModel := m.(model)
-	return finalModel.selected, finalModel.selectedFolder, finalModel.err
+	return finalModel.selected, finalModel.selectedFolder, finalModel.selectedProfile, finalModel.err
 }
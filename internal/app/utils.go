@@ -2,14 +2,15 @@ package app
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
+	"sync"
 	"unicode"
 	"unicode/utf8"
 
@@ -19,6 +20,9 @@ import (
 const (
 	defaultConfigPath  = "scaffold.config.json"
 	defaultGenerateOut = "./new-app"
+	defaultTemplateOut = "./template"
+
+	templateMetadataFile = ".scaffo-template.json"
 )
 
 var (
@@ -77,19 +81,30 @@ var (
 )
 
 // MatchIgnore checks if a file/folder should be ignored based on config ignore patterns and .scaffoldignore.
+// The .scaffoldignore patterns have gitignore semantics (see IgnoreRule).
 func MatchIgnore(path string, isDir bool, ignoreFolders, ignoreFiles []string, scaffoldIgnorePatterns []string) bool {
+	if configIgnoreSource(path, isDir, ignoreFolders, ignoreFiles) != "" {
+		return true
+	}
+	rules := ParseIgnoreRules(scaffoldIgnoreFile, strings.Join(scaffoldIgnorePatterns, "\n"))
+	return NewIgnoreMatcher(rules...).Ignored(path, isDir)
+}
+
+// configIgnoreSource checks a path against the ignoreFolders and ignoreFiles
+// lists and names the list and pattern that exclude it, or returns "".
+func configIgnoreSource(path string, isDir bool, ignoreFolders, ignoreFiles []string) string {
 	path = filepath.ToSlash(path)
 	base := filepath.Base(path)
 	if isDir {
 		for _, pat := range ignoreFolders {
 			if matchGlob(base, pat) || matchGlob(path, pat) {
-				return true
+				return "ignoreFolders: " + pat
 			}
 		}
 	} else {
 		for _, pat := range ignoreFiles {
 			if matchGlob(base, pat) || matchGlob(path, pat) {
-				return true
+				return "ignoreFiles: " + pat
 			}
 		}
 		// Check if any parent directory matches ignoreFolders
@@ -99,17 +114,12 @@ func MatchIgnore(path string, isDir bool, ignoreFolders, ignoreFiles []string, s
 			baseName := parts[i]
 			for _, pat := range ignoreFolders {
 				if matchGlob(baseName, pat) || matchGlob(subPath, pat) {
-					return true
+					return "ignoreFolders: " + pat
 				}
 			}
 		}
 	}
-	for _, pat := range scaffoldIgnorePatterns {
-		if matchGlob(path, pat) || matchGlob(base, pat) {
-			return true
-		}
-	}
-	return false
+	return ""
 }
 
 // MatchInclude returns true when a path matches an explicit include glob.
@@ -123,6 +133,36 @@ func MatchInclude(path string, includePatterns []string) bool {
 	return false
 }
 
+// mayContainInclude reports whether an include glob may match a path below
+// the directory dir, so that the walk has to descend into it.
+func mayContainInclude(dir string, includePatterns []string) bool {
+	dirParts := strings.Split(filepath.ToSlash(dir), "/")
+	for _, pat := range includePatterns {
+		patParts := strings.Split(filepath.ToSlash(strings.TrimSpace(pat)), "/")
+		if globPrefixMatches(patParts, dirParts) {
+			return true
+		}
+	}
+	return false
+}
+
+// globPrefixMatches reports whether the leading segments of a glob match
+// dirParts and leave segments to match the rest of a longer path.
+func globPrefixMatches(patParts, dirParts []string) bool {
+	for i, part := range dirParts {
+		if i >= len(patParts) {
+			return false
+		}
+		if patParts[i] == "**" {
+			return true
+		}
+		if ok, err := doublestar.Match(patParts[i], part); err != nil || !ok {
+			return false
+		}
+	}
+	return len(patParts) > len(dirParts)
+}
+
 func matchGlob(path, pattern string) bool {
 	pattern = strings.TrimSpace(pattern)
 	if pattern == "" {
@@ -137,24 +177,59 @@ func matchGlob(path, pattern string) bool {
 	return matched
 }
 
-func loadScaffoldIgnore(sourceRoot string) []string {
-	filePath := filepath.Join(sourceRoot, ".scaffoldignore")
-	f, err := os.Open(filePath)
-	if err != nil {
-		return nil
+// stdinReader buffers the process's standard input for every prompt, so that
+// the menu and the runs it starts read from one buffer.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// lineReader returns r as a *bufio.Reader, wrapping it only when needed so
+// that successive prompts share one buffer.
+func lineReader(r io.Reader) *bufio.Reader {
+	if br, ok := r.(*bufio.Reader); ok {
+		return br
 	}
-	defer f.Close()
+	if r == os.Stdin {
+		return stdinReader
+	}
+	return bufio.NewReader(r)
+}
 
-	var patterns []string
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		patterns = append(patterns, filepath.ToSlash(line))
+type readResult struct {
+	text string
+	err  error
+}
+
+// pendingReads holds the reads that readLine gave up on, by reader.
+var (
+	pendingMu    sync.Mutex
+	pendingReads = map[*bufio.Reader]chan readResult{}
+)
+
+// readLine reads one line from r, giving up when ctx is cancelled so that an
+// interrupt is not blocked by a pending prompt. A read given up on keeps
+// running, and the line it reads is returned by the next readLine on r
+// instead of being lost.
+func readLine(ctx context.Context, r *bufio.Reader) (string, error) {
+	pendingMu.Lock()
+	ch, ok := pendingReads[r]
+	if !ok {
+		ch = make(chan readResult, 1)
+		pendingReads[r] = ch
+		go func() {
+			text, err := r.ReadString('\n')
+			ch <- readResult{text, err}
+		}()
+	}
+	pendingMu.Unlock()
+
+	select {
+	case l := <-ch:
+		pendingMu.Lock()
+		delete(pendingReads, r)
+		pendingMu.Unlock()
+		return l.text, l.err
+	case <-ctx.Done():
+		return "", ctx.Err()
 	}
-	return patterns
 }
 
 func resolveConfigPath(path string) string {
@@ -178,6 +253,10 @@ func applyRenameRules(rel string, rules []RenameRule) string {
 		if from == "" {
 			continue
 		}
+		if len(rule.Include) > 0 || len(rule.Exclude) > 0 {
+			result = renameInScope(rel, result, rule)
+			continue
+		}
 		// Simple string replacement for path segments
 		// This handles "MyProject/File.cs" -> "{{PROJECT_NAME}}/File.cs"
 		// and "File.MyProject.cs" -> "File.{{PROJECT_NAME}}.cs"
@@ -268,98 +347,10 @@ func looksBinary(path string) (bool, error) {
 	return false, nil
 }
 
-func collectVariableValues(vars map[string]Variable) (map[string]string, error) {
-	values := make(map[string]string, len(vars))
-	reader := bufio.NewReader(os.Stdin)
-	keys := make([]string, 0, len(vars))
-	for k := range vars {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	for _, name := range keys {
-		v := vars[name]
-		if envVal, ok := os.LookupEnv("SCAFFO_" + name); ok && strings.TrimSpace(envVal) != "" {
-			values[name] = envVal
-			continue
-		}
-		if v.From != "" {
-			continue
-		}
-		prompt := v.Description
-		if prompt == "" {
-			prompt = "Enter value"
-		}
-		defaultHint := ""
-		if v.Default != "" {
-			defaultHint = " [" + v.Default + "]"
-		}
-		for {
-			fmt.Printf("%s (%s)%s: ", name, prompt, defaultHint)
-			text, err := reader.ReadString('\n')
-			if err != nil && !errors.Is(err, io.EOF) {
-				return nil, err
-			}
-			text = strings.TrimSpace(text)
-			if text == "" {
-				text = v.Default
-			}
-			if text == "" && v.Required {
-				fmt.Println("Value required.")
-				continue
-			}
-			values[name] = text
-			break
-		}
-	}
-
-	for _, name := range keys {
-		if _, ok := values[name]; ok {
-			continue
-		}
-		v := vars[name]
-		if v.From != "" {
-			if source, ok := values[v.From]; ok && source != "" {
-				values[name] = applyTransform(source, v.Transform)
-				continue
-			}
-		}
-		if v.Default != "" {
-			values[name] = v.Default
-			continue
-		}
-		if v.Required {
-			return nil, fmt.Errorf("missing value for %s", name)
-		}
-		values[name] = ""
-	}
-
-	return values, nil
-}
-
-func applyTransform(input, transform string) string {
-	switch strings.ToLower(transform) {
-	case "", "identity":
-		return input
-	case "slug-kebab":
-		return slugify(input, '-')
-	case "slug-snake":
-		return slugify(input, '_')
-	case "upper":
-		return strings.ToUpper(input)
-	case "lower":
-		return strings.ToLower(input)
-	case "title":
-		return titleCase(input)
-	default:
-		return input
-	}
-}
-
 func slugify(input string, sep rune) string {
 	var b strings.Builder
 	lastWasSep := true
-	for _, r := range input {
+	for _, r := range transliterate(input) {
 		if unicode.IsLetter(r) || unicode.IsDigit(r) {
 			b.WriteRune(unicode.ToLower(r))
 			lastWasSep = false
@@ -389,14 +380,6 @@ func titleCase(input string) string {
 	return strings.Join(words, " ")
 }
 
-func replaceTokens(input string, values map[string]string, start, end string) string {
-	for name, value := range values {
-		token := start + name + end
-		input = strings.ReplaceAll(input, token, value)
-	}
-	return input
-}
-
 func defaultTokenDelims(token map[string]string) (string, string) {
 	start := "{{"
 	end := "}}"
@@ -411,6 +394,27 @@ func defaultTokenDelims(token map[string]string) (string, string) {
 	return start, end
 }
 
+// checkTokenOverrides requires every override to have a glob and both delimiters.
+func checkTokenOverrides(overrides []TokenOverride) error {
+	for i, o := range overrides {
+		if strings.TrimSpace(o.Glob) == "" || strings.TrimSpace(o.Start) == "" || strings.TrimSpace(o.End) == "" {
+			return fmt.Errorf("tokenOverrides[%d]: glob, start and end are required", i)
+		}
+	}
+	return nil
+}
+
+// tokenDelimsFor returns the token delimiters for the source path rel: those
+// of the first matching TokenOverride, otherwise the configured Token.
+func tokenDelimsFor(cfg *Config, rel string) (string, string) {
+	for _, o := range cfg.TokenOverrides {
+		if matchGlob(rel, o.Glob) {
+			return strings.TrimSpace(o.Start), strings.TrimSpace(o.End)
+		}
+	}
+	return defaultTokenDelims(cfg.Token)
+}
+
 func splitIntoWords(s string) []string {
 	var words []string
 	var currentWord strings.Builder
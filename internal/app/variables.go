@@ -0,0 +1,460 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Variable types understood by validateValue.
+const (
+	VarString = "string"
+	VarInt    = "int"
+	VarBool   = "bool"
+	VarEnum   = "enum"
+	VarList   = "list"
+)
+
+const defaultListSeparator = ","
+
+// checkVariableDefinition reports configuration mistakes (unknown type, enum
+// without options, bad pattern) before any value is collected.
+func checkVariableDefinition(name string, v Variable) error {
+	switch strings.ToLower(v.Type) {
+	case "", VarString:
+		if v.Pattern != "" {
+			if _, err := compilePattern(v.Pattern); err != nil {
+				return fmt.Errorf("variable %s: invalid pattern: %w", name, err)
+			}
+		}
+	case VarInt:
+		if v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+			return fmt.Errorf("variable %s: min %d is greater than max %d", name, *v.Min, *v.Max)
+		}
+	case VarBool, VarList:
+	case VarEnum:
+		if len(v.Options) == 0 {
+			return fmt.Errorf("variable %s: enum requires options", name)
+		}
+	default:
+		return fmt.Errorf("variable %s: unknown type %q", name, v.Type)
+	}
+	for _, step := range v.Transform.steps() {
+		if _, ok := transforms[strings.ToLower(step)]; !ok {
+			return fmt.Errorf("variable %s: unknown transform %q", name, step)
+		}
+	}
+	return nil
+}
+
+// derivationOrder orders keys so that every variable follows the variable it
+// is derived from, reporting unknown sources and derivation cycles.
+func derivationOrder(keys []string, vars map[string]Variable) ([]string, error) {
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := make(map[string]int, len(keys))
+	order := make([]string, 0, len(keys))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			cycle := append(slices.Clone(path[slices.Index(path, name):]), name)
+			return fmt.Errorf("variables derive from each other: %s", strings.Join(cycle, " -> "))
+		}
+		state[name] = visiting
+		path = append(path, name)
+		if from := vars[name].From; from != "" {
+			if _, ok := vars[from]; !ok {
+				return fmt.Errorf("variable %s: from refers to unknown variable %s", name, from)
+			}
+			if err := visit(from); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range keys {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// validateValue checks raw against the variable's type and returns the
+// normalized value (e.g. "yes" becomes "true" for bool variables).
+// Empty values are accepted as-is; required checks happen in the caller.
+func validateValue(v Variable, raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return "", nil
+	}
+	switch strings.ToLower(v.Type) {
+	case "", VarString:
+		if v.Pattern != "" {
+			re, err := compilePattern(v.Pattern)
+			if err != nil {
+				return "", err
+			}
+			if !re.MatchString(raw) {
+				return "", fmt.Errorf("must match pattern %s", v.Pattern)
+			}
+		}
+		return raw, nil
+	case VarInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("must be a whole number")
+		}
+		if v.Min != nil && n < *v.Min {
+			return "", fmt.Errorf("must be at least %d", *v.Min)
+		}
+		if v.Max != nil && n > *v.Max {
+			return "", fmt.Errorf("must be at most %d", *v.Max)
+		}
+		return strconv.Itoa(n), nil
+	case VarBool:
+		switch strings.ToLower(value) {
+		case "y", "yes", "true", "t", "1", "on":
+			return "true", nil
+		case "n", "no", "false", "f", "0", "off":
+			return "false", nil
+		}
+		return "", fmt.Errorf("must be yes/no or true/false")
+	case VarEnum:
+		for _, opt := range v.Options {
+			if strings.EqualFold(opt, value) {
+				return opt, nil
+			}
+		}
+		return "", fmt.Errorf("must be one of: %s", strings.Join(v.Options, ", "))
+	case VarList:
+		sep := v.Separator
+		if sep == "" {
+			sep = defaultListSeparator
+		}
+		var items []string
+		for _, item := range strings.Split(value, sep) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return strings.Join(items, sep), nil
+	default:
+		return "", fmt.Errorf("unknown type %q", v.Type)
+	}
+}
+
+// typeHint describes the accepted input for a prompt, or "" for plain strings.
+func typeHint(v Variable) string {
+	switch strings.ToLower(v.Type) {
+	case VarInt:
+		switch {
+		case v.Min != nil && v.Max != nil:
+			return fmt.Sprintf("number %d-%d", *v.Min, *v.Max)
+		case v.Min != nil:
+			return fmt.Sprintf("number >= %d", *v.Min)
+		case v.Max != nil:
+			return fmt.Sprintf("number <= %d", *v.Max)
+		}
+		return "number"
+	case VarBool:
+		return "y/n"
+	case VarEnum:
+		return strings.Join(v.Options, "|")
+	case VarList:
+		sep := v.Separator
+		if sep == "" {
+			sep = defaultListSeparator
+		}
+		return fmt.Sprintf("%q-separated list", sep)
+	}
+	return ""
+}
+
+// compilePattern anchors pattern so that it has to match the whole value.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// providedValue is a variable value supplied without prompting, with a
+// description of where it came from for error messages.
+type providedValue struct {
+	value  string
+	origin string
+}
+
+// collectVariableValues resolves every variable. Values supplied through
+// sources (--set, then the values file, then SCAFFO_<NAME>) win over prompts
+// and defaults; prompting is skipped entirely in non-interactive mode.
+func collectVariableValues(ctx context.Context, vars map[string]Variable, sources ValueSources, streams IOStreams) (map[string]string, error) {
+	values := make(map[string]string, len(vars))
+	reader := lineReader(streams.In)
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, name := range keys {
+		if err := checkVariableDefinition(name, vars[name]); err != nil {
+			return nil, err
+		}
+	}
+	order, err := derivationOrder(keys, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	provided, err := sources.lookup(vars, streams)
+	if err != nil {
+		return nil, err
+	}
+
+	if sources.NonInteractive {
+		if missing := missingVariables(keys, vars, provided); len(missing) > 0 {
+			return nil, fmt.Errorf("missing required variable(s): %s (provide them with --set NAME=value, --values or SCAFFO_<NAME>)", strings.Join(missing, ", "))
+		}
+	}
+
+	for _, name := range keys {
+		v := vars[name]
+		if p, ok := provided[name]; ok {
+			val, err := validateValue(v, p.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s from %s: %w", name, p.origin, err)
+			}
+			if val == "" && v.Required {
+				return nil, fmt.Errorf("empty value for required variable %s from %s", name, p.origin)
+			}
+			values[name] = val
+			continue
+		}
+		if v.From != "" || sources.NonInteractive {
+			continue
+		}
+		prompt := v.Description
+		if prompt == "" {
+			prompt = "Enter value"
+		}
+		if hint := typeHint(v); hint != "" {
+			prompt += ", " + hint
+		}
+		defaultHint := ""
+		if v.Default != "" {
+			defaultHint = " [" + v.Default + "]"
+		}
+		for {
+			fmt.Fprintf(streams.Out, "%s (%s)%s: ", name, prompt, defaultHint)
+			text, err := readLine(ctx, reader)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			eof := errors.Is(err, io.EOF)
+			text = strings.TrimSpace(text)
+			if text == "" {
+				text = v.Default
+			}
+			if text == "" && v.Required {
+				if eof {
+					return nil, fmt.Errorf("missing value for %s", name)
+				}
+				fmt.Fprintln(streams.Out, "Value required.")
+				continue
+			}
+			val, err := validateValue(v, text)
+			if err != nil {
+				if eof {
+					return nil, fmt.Errorf("invalid value for %s: %w", name, err)
+				}
+				fmt.Fprintf(streams.Out, "Invalid value for %s: %v. Please try again.\n", name, err)
+				continue
+			}
+			values[name] = val
+			break
+		}
+	}
+
+	// Derived variables are resolved after their sources
+	for _, name := range order {
+		if _, ok := values[name]; ok {
+			continue
+		}
+		v := vars[name]
+		if v.From != "" {
+			if source, ok := values[v.From]; ok && source != "" {
+				val, err := validateValue(v, applyTransform(source, v.Transform))
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for %s derived from %s: %w", name, v.From, err)
+				}
+				values[name] = val
+				continue
+			}
+		}
+		if v.Default != "" {
+			val, err := validateValue(v, v.Default)
+			if err != nil {
+				return nil, fmt.Errorf("invalid default for %s: %w", name, err)
+			}
+			values[name] = val
+			continue
+		}
+		if v.Required {
+			return nil, fmt.Errorf("missing value for %s", name)
+		}
+		values[name] = ""
+	}
+
+	return values, nil
+}
+
+// missingVariables lists required variables that have no supplied value, no
+// default and no source to derive from.
+func missingVariables(keys []string, vars map[string]Variable, provided map[string]providedValue) []string {
+	var resolvable func(name string, depth int) bool
+	resolvable = func(name string, depth int) bool {
+		if _, ok := provided[name]; ok {
+			return true
+		}
+		v, ok := vars[name]
+		if !ok || depth > len(vars) {
+			return false
+		}
+		if v.Default != "" {
+			return true
+		}
+		return v.From != "" && resolvable(v.From, depth+1)
+	}
+
+	var missing []string
+	for _, name := range keys {
+		if vars[name].Required && !resolvable(name, 0) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// lookup gathers the non-interactive values in increasing order of precedence:
+// SCAFFO_<NAME> environment variables, the values file, then --set.
+func (s ValueSources) lookup(vars map[string]Variable, streams IOStreams) (map[string]providedValue, error) {
+	provided := map[string]providedValue{}
+	for name := range vars {
+		if envVal, ok := os.LookupEnv("SCAFFO_" + name); ok && strings.TrimSpace(envVal) != "" {
+			provided[name] = providedValue{value: envVal, origin: "SCAFFO_" + name}
+		}
+	}
+
+	if strings.TrimSpace(s.ValuesFile) != "" {
+		fileValues, err := loadValuesFile(s.ValuesFile, vars)
+		if err != nil {
+			return nil, fmt.Errorf("loading values file: %w", err)
+		}
+		for name, val := range fileValues {
+			if _, ok := vars[name]; !ok {
+				fmt.Fprintf(streams.ErrOut, "Warning: %s sets unknown variable %s\n", s.ValuesFile, name)
+				continue
+			}
+			provided[name] = providedValue{value: val, origin: s.ValuesFile}
+		}
+	}
+
+	for name, val := range s.Set {
+		if _, ok := vars[name]; !ok {
+			return nil, fmt.Errorf("--set %s: unknown variable", name)
+		}
+		provided[name] = providedValue{value: val, origin: "--set"}
+	}
+	return provided, nil
+}
+
+// loadValuesFile reads a flat NAME: value mapping from a YAML or JSON file.
+// Non-string values are converted to their textual form; lists are joined
+// with the variable's separator.
+func loadValuesFile(path string, vars map[string]Variable) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	default:
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(raw))
+	for name, val := range raw {
+		sep := vars[name].Separator
+		if sep == "" {
+			sep = defaultListSeparator
+		}
+		text, err := stringifyValue(val, sep)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values[name] = text
+	}
+	return values, nil
+}
+
+func stringifyValue(val any, sep string) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			text, err := stringifyValue(item, sep)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, text)
+		}
+		return strings.Join(items, sep), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", val)
+	}
+}
+
+// ParseSetValue splits a NAME=value argument as accepted by --set.
+func ParseSetValue(arg string) (string, string, error) {
+	name, value, ok := strings.Cut(arg, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid --set %q: expected NAME=value", arg)
+	}
+	return name, value, nil
+}
@@ -0,0 +1,197 @@
+// Package scaffo exposes scaffo's project scaffolding as a library so other
+// tools can embed it and react to failures instead of parsing CLI output.
+package scaffo
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+// Options describes a single scaffolding run.
+type Options struct {
+	// ConfigPath is the scaffold config file (default scaffold.config.json).
+	ConfigPath string
+	// SourceRoot overrides the config's sourceRoot when set.
+	SourceRoot string
+	// OutPath is the destination for the generated project.
+	OutPath string
+	// CopyConfig copies the config file into the generated project.
+	CopyConfig bool
+	// Set supplies variable values; it takes precedence over ValuesFile,
+	// which takes precedence over SCAFFO_<NAME> environment variables.
+	Set map[string]string
+	// ValuesFile is a YAML or JSON file mapping variable names to values.
+	ValuesFile string
+	// NonInteractive fails with the list of missing required variables
+	// instead of prompting on Stdin.
+	NonInteractive bool
+	// DryRun writes nothing; the plan is printed to Stdout in PlanFormat
+	// ("text" or "json") and returned in Result.Plan.
+	DryRun     bool
+	PlanFormat string
+	// DiffGlob and DiffLimit select templated files whose unified diff
+	// against the source is included in the plan; either implies DryRun.
+	DiffGlob  string
+	DiffLimit int
+	// Overwrite selects how files that already exist in OutPath are handled.
+	// When empty, an existing OutPath is an error.
+	Overwrite string
+	// Strict fails the run when tokens are left unresolved in the output;
+	// otherwise they are reported in Result.Unresolved.
+	Strict bool
+	// UseGitignore also skips paths excluded by the source tree's .gitignore
+	// files and .git/info/exclude.
+	UseGitignore bool
+	// Verbose prints what excluded each skipped path to Stdout.
+	Verbose bool
+	// Profile applies one of the config's profiles.
+	Profile string
+}
+
+// BuildOptions describes a template build, as with scaffo build-template.
+type BuildOptions struct {
+	// ConfigPath is the scaffold config file (default scaffold.config.json).
+	ConfigPath string
+	// SourceRoot overrides the config's sourceRoot when set.
+	SourceRoot string
+	// OutputDir is where the template is written (default: the config's
+	// templateRoot).
+	OutputDir string
+	// UseGitignore and Verbose behave as in Options.
+	UseGitignore bool
+	Verbose      bool
+}
+
+// GenerateOptions describes generating a project from a template built with
+// BuildTemplate, as with scaffo generate.
+type GenerateOptions struct {
+	// TemplateRoot is the built template (default ./template).
+	TemplateRoot string
+	// OutPath is the destination for the generated project.
+	OutPath string
+	// CopyConfig copies the template metadata, or ConfigPath when set, into
+	// the generated project.
+	CopyConfig bool
+	// ConfigPath optionally overrides the variables and hooks recorded in
+	// the template.
+	ConfigPath string
+	// Set, ValuesFile, NonInteractive, Overwrite and Strict behave as in
+	// Options.
+	Set            map[string]string
+	ValuesFile     string
+	NonInteractive bool
+	Overwrite      string
+	Strict         bool
+}
+
+// Overwrite strategies for Options.Overwrite. OverwriteInteractive asks on
+// Stdin for every conflicting file.
+const (
+	OverwriteForce       = app.OverwriteForce
+	OverwriteSkip        = app.OverwriteSkip
+	OverwriteBackup      = app.OverwriteBackup
+	OverwriteInteractive = app.OverwriteInteractive
+)
+
+// Plan lists the operations a run performs; see Options.DryRun.
+type (
+	Plan             = app.Plan
+	PlannedOperation = app.PlannedOperation
+	ReplacementHit   = app.ReplacementHit
+	UnresolvedToken  = app.UnresolvedToken
+)
+
+// Result reports what a run, build or generation produced. Created paths are
+// slash-separated and relative to OutPath; skipped paths are relative to the
+// source root. Overwritten, SkippedExisting and BackedUp list files that
+// already existed. SkippedBy names the ignore rule or condition that excluded
+// each skipped path. Unresolved lists tokens left in the output that name no
+// variable.
+type Result = app.Result
+
+// Scaffolder runs scaffo with the given streams. Variable prompts read from
+// Stdin, and progress and hook output go to Stdout and Stderr. The zero value
+// is ready to use: it never prompts and discards all output.
+type Scaffolder struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// New returns a Scaffolder with no input and discarded output.
+func New() *Scaffolder {
+	return &Scaffolder{}
+}
+
+// Run scaffolds a project. On failure the returned Result, when non-nil,
+// describes the work done before the error occurred.
+func (s *Scaffolder) Run(ctx context.Context, opts Options) (*Result, error) {
+	return app.Run(ctx, app.RunOptions{
+		ConfigPath:   opts.ConfigPath,
+		SourceRoot:   opts.SourceRoot,
+		OutPath:      opts.OutPath,
+		CopyConfig:   opts.CopyConfig,
+		Values:       valueSources(opts.Set, opts.ValuesFile, opts.NonInteractive),
+		DryRun:       opts.DryRun,
+		PlanFormat:   opts.PlanFormat,
+		DiffGlob:     opts.DiffGlob,
+		DiffLimit:    opts.DiffLimit,
+		Overwrite:    opts.Overwrite,
+		Strict:       opts.Strict,
+		UseGitignore: opts.UseGitignore,
+		Verbose:      opts.Verbose,
+		Profile:      opts.Profile,
+		Streams:      s.streams(),
+	})
+}
+
+// BuildTemplate turns a source project into a reusable template. On failure
+// the returned Result, when non-nil, describes the work done before the error
+// occurred.
+func (s *Scaffolder) BuildTemplate(ctx context.Context, opts BuildOptions) (*Result, error) {
+	return app.BuildTemplate(ctx, app.BuildOptions{
+		ConfigPath:   opts.ConfigPath,
+		SourceRoot:   opts.SourceRoot,
+		OutputDir:    opts.OutputDir,
+		UseGitignore: opts.UseGitignore,
+		Verbose:      opts.Verbose,
+		Streams:      s.streams(),
+	})
+}
+
+// Generate creates a project from a template built with BuildTemplate. On
+// failure the returned Result, when non-nil, describes the work done before
+// the error occurred.
+func (s *Scaffolder) Generate(ctx context.Context, opts GenerateOptions) (*Result, error) {
+	return app.Generate(ctx, app.GenerateOptions{
+		TemplateRoot: opts.TemplateRoot,
+		OutPath:      opts.OutPath,
+		CopyConfig:   opts.CopyConfig,
+		ConfigPath:   opts.ConfigPath,
+		Values:       valueSources(opts.Set, opts.ValuesFile, opts.NonInteractive),
+		Overwrite:    opts.Overwrite,
+		Strict:       opts.Strict,
+		Streams:      s.streams(),
+	})
+}
+
+func valueSources(set map[string]string, valuesFile string, nonInteractive bool) app.ValueSources {
+	return app.ValueSources{Set: set, ValuesFile: valuesFile, NonInteractive: nonInteractive}
+}
+
+func (s *Scaffolder) streams() app.IOStreams {
+	streams := app.IOStreams{In: s.Stdin, Out: s.Stdout, ErrOut: s.Stderr}
+	if streams.In == nil {
+		streams.In = strings.NewReader("")
+	}
+	if streams.Out == nil {
+		streams.Out = io.Discard
+	}
+	if streams.ErrOut == nil {
+		streams.ErrOut = io.Discard
+	}
+	return streams
+}
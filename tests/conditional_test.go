@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func conditionalConfig(paths map[string]string) *app.Config {
+	return &app.Config{
+		ConditionalPaths: paths,
+		Variables: map[string]app.Variable{
+			"ENABLE_DOCKER": {Type: "bool", Default: "false"},
+			"ENABLE_AUTH":   {Type: "bool", Default: "true"},
+			"DATABASE":      {Type: "enum", Options: []string{"none", "postgres"}, Default: "postgres"},
+		},
+	}
+}
+
+var conditionalFiles = map[string]string{
+	"docker/Dockerfile":  "FROM scratch",
+	"src/auth/login.go":  "package auth",
+	"src/db/migrate.sql": "-- migrations",
+	"src/main.go":        "package main",
+}
+
+func TestConditionalPathsDropSubtrees(t *testing.T) {
+	root, configPath := writeProject(t, conditionalFiles, conditionalConfig(map[string]string{
+		"docker/**":   "ENABLE_DOCKER == true",
+		"src/auth/**": `ENABLE_AUTH && (DATABASE != "none")`,
+		"src/db":      "!(DATABASE == 'none')",
+	}))
+
+	res, err := runProject(configPath, map[string]string{"DATABASE": "none"})
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	out := projectOut(root)
+	for _, rel := range []string{"docker", "src/auth", "src/db"} {
+		if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(rel))); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be excluded", rel)
+		}
+		if !slices.Contains(res.Skipped, rel+"/") {
+			t.Fatalf("expected %s/ in skipped paths, got %v", rel, res.Skipped)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "src", "main.go")); err != nil {
+		t.Fatalf("expected unconditional file to be written: %v", err)
+	}
+
+	os.RemoveAll(out)
+	if _, err := runProject(configPath, map[string]string{"ENABLE_DOCKER": "yes"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	for _, rel := range []string{"docker/Dockerfile", "src/auth/login.go", "src/db/migrate.sql"} {
+		if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(rel))); err != nil {
+			t.Fatalf("expected %s to be included: %v", rel, err)
+		}
+	}
+}
+
+func TestConditionalPathsInvalidExpression(t *testing.T) {
+	_, configPath := writeProject(t, conditionalFiles, conditionalConfig(map[string]string{
+		"docker/**": "ENABLE_DOCKER == ",
+	}))
+	if _, err := app.LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "docker/**") {
+		t.Fatalf("expected parse error naming the glob, got %v", err)
+	}
+}
+
+func TestConditionalPathsUnknownVariable(t *testing.T) {
+	_, configPath := writeProject(t, conditionalFiles, conditionalConfig(map[string]string{
+		"docker/**": "ENABLE_DOKCER",
+	}))
+	if _, err := runProject(configPath, nil); err == nil || !strings.Contains(err.Error(), "unknown variable ENABLE_DOKCER") {
+		t.Fatalf("expected unknown variable error, got %v", err)
+	}
+}
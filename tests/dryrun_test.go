@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func writeDryRunProject(t *testing.T) (string, string) {
+	t.Helper()
+	return writeProjectIn(t, "Banker", map[string]string{
+		"src/Banker.cs":       "namespace Banker; // Banker",
+		"assets/logo.png":     "\x89PNG",
+		"node_modules/pkg.js": "ignored",
+		"app.log":             "ignored",
+	}, &app.Config{
+		Replacements: []app.Replacement{{Find: "Banker", ReplaceWith: "{{APP}}"}},
+		RenameRules:  []app.RenameRule{{From: "Banker", To: "{{APP}}"}},
+		Variables:    map[string]app.Variable{"APP": {Type: "string", Default: "Vault"}},
+	})
+}
+
+func TestDryRunPlanWritesNothing(t *testing.T) {
+	root, configPath := writeDryRunProject(t)
+	outPath := filepath.Join(root, "Vault")
+	var out bytes.Buffer
+	res, err := app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    outPath,
+		DryRun:     true,
+		Values:     app.ValueSources{NonInteractive: true},
+		Streams:    app.IOStreams{Out: &out, ErrOut: io.Discard},
+	})
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Fatalf("dry run must not create the output directory")
+	}
+
+	var templated *app.PlannedOperation
+	for i, op := range res.Plan.Operations {
+		if op.Kind == app.OpTemplated {
+			templated = &res.Plan.Operations[i]
+		}
+		if op.Source == "assets/logo.png" && op.Kind != app.OpStatic {
+			t.Fatalf("logo.png should be static, got %s", op.Kind)
+		}
+	}
+	if templated == nil || templated.Destination != "src/Vault.cs" {
+		t.Fatalf("expected src/Banker.cs to be renamed, got %+v", templated)
+	}
+	if len(templated.Replacements) != 1 || templated.Replacements[0].Count != 2 {
+		t.Fatalf("expected Banker replacement to fire twice, got %+v", templated.Replacements)
+	}
+	wantSkipped := []string{"app.log", "node_modules/"}
+	if strings.Join(res.Plan.Skipped, ",") != strings.Join(wantSkipped, ",") {
+		t.Fatalf("skipped = %v want %v", res.Plan.Skipped, wantSkipped)
+	}
+	if !strings.Contains(out.String(), "src/Banker.cs -> src/Vault.cs") {
+		t.Fatalf("text plan missing rename:\n%s", out.String())
+	}
+}
+
+func TestDryRunJSONOutput(t *testing.T) {
+	root, configPath := writeDryRunProject(t)
+	var out, progress bytes.Buffer
+	_, err := app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    filepath.Join(root, "Vault"),
+		DryRun:     true,
+		PlanFormat: app.PlanFormatJSON,
+		Values:     app.ValueSources{NonInteractive: true},
+		Streams:    app.IOStreams{Out: &out, ErrOut: &progress},
+	})
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	var plan app.Plan
+	if err := json.Unmarshal(out.Bytes(), &plan); err != nil {
+		t.Fatalf("stdout is not a JSON plan: %v\n%s", err, out.String())
+	}
+	if len(plan.Operations) == 0 || progress.Len() == 0 {
+		t.Fatalf("expected operations on stdout and progress on stderr")
+	}
+}
+
+func TestDiffPreviewShowsRewrittenLines(t *testing.T) {
+	root, configPath := writeDryRunProject(t)
+	outPath := filepath.Join(root, "Vault")
+	var out bytes.Buffer
+	res, err := app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    outPath,
+		DiffGlob:   "src/**",
+		Values:     app.ValueSources{NonInteractive: true},
+		Streams:    app.IOStreams{Out: &out, ErrOut: io.Discard},
+	})
+	if err != nil {
+		t.Fatalf("diff run failed: %v", err)
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Fatalf("--diff must not write the output directory")
+	}
+	want := "--- a/src/Banker.cs\n+++ b/src/Vault.cs\n@@ -1 +1 @@\n-namespace Banker; // Banker\n\\ No newline at end of file\n+namespace Vault; // Vault\n\\ No newline at end of file\n"
+	var got string
+	for _, op := range res.Plan.Operations {
+		got += op.Diff
+	}
+	if got != want {
+		t.Fatalf("diff mismatch:\n%s\nwant:\n%s", got, want)
+	}
+	if !strings.Contains(out.String(), want) {
+		t.Fatalf("diff not printed:\n%s", out.String())
+	}
+}
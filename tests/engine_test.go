@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func goTemplateConfig() *app.Config {
+	return &app.Config{
+		Engine: app.EngineGoTemplate,
+		Variables: map[string]app.Variable{
+			"PROJECT_NAME":  {Type: "string", Required: true},
+			"ENABLE_DOCKER": {Type: "bool", Default: "false"},
+			"SERVICES":      {Type: "list", Default: "api,worker"},
+		},
+	}
+}
+
+func TestGoTemplateEngineRendersContentAndPaths(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		"{{.PROJECT_NAME | slugify}}/README.md": "# {{.PROJECT_NAME | titleCase}}\n" +
+			"{{if .ENABLE_DOCKER}}docker{{else}}no docker{{end}}\n" +
+			"{{range .SERVICES}}- {{toPascalCase .}}\n{{end}}" +
+			"{{toSnakeCase .PROJECT_NAME}} {{toCamelCase .PROJECT_NAME}}\n",
+	}, goTemplateConfig())
+
+	if _, err := runProject(configPath, map[string]string{"PROJECT_NAME": "billing service"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	got := readFile(t, filepath.Join(projectOut(root), "billing-service", "README.md"))
+	want := "# Billing Service\nno docker\n- Api\n- Worker\nbilling_service billingService\n"
+	if got != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGoTemplateEngineUsesConfiguredDelims(t *testing.T) {
+	cfg := goTemplateConfig()
+	cfg.Token = map[string]string{"start": "[[", "end": "]]"}
+	root, configPath := writeProject(t, map[string]string{
+		"chart.yaml": "name: [[.PROJECT_NAME]]\nimage: {{ .Values.image }}\n",
+	}, cfg)
+
+	if _, err := runProject(configPath, map[string]string{"PROJECT_NAME": "api"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	got := readFile(t, filepath.Join(projectOut(root), "chart.yaml"))
+	if got != "name: api\nimage: {{ .Values.image }}\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+func TestGoTemplateEngineErrorsNameFileAndLine(t *testing.T) {
+	_, configPath := writeProject(t, map[string]string{
+		"docs/guide.md": "line one\n{{.PROJECT_NAME}}\n{{.MISSING}}\n",
+	}, goTemplateConfig())
+
+	_, err := runProject(configPath, map[string]string{"PROJECT_NAME": "api"})
+	if err == nil || !strings.Contains(err.Error(), "docs/guide.md:3") {
+		t.Fatalf("expected error pointing at docs/guide.md:3, got %v", err)
+	}
+}
+
+func TestUnknownEngineRejected(t *testing.T) {
+	_, configPath := writeProject(t, map[string]string{"a.txt": "a"}, &app.Config{Engine: "jinja"})
+	if _, err := app.LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "jinja") {
+		t.Fatalf("expected unknown engine error, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestConfigExtendsMergesInOrder(t *testing.T) {
+	root := writeConfigFiles(t, map[string]string{
+		"shared/base.yaml": `
+sourceRoot: ./base-src
+useGitignore: true
+ignoreFolders: [node_modules, .git]
+staticFiles: ["**/*.png"]
+token:
+  start: "{{"
+  end: "}}"
+variables:
+  ORG:
+    type: string
+    required: true
+    default: Acme
+  PORT:
+    type: int
+    default: 8080
+hooks:
+  postGenerate:
+    - command: git init
+`,
+		"shared/go.json": `{
+  "extends": ["base.yaml"],
+  "ignoreFolders": ["vendor", ".git"],
+  "hooks": {"postGenerate": [{"command": "go mod tidy"}]}
+}`,
+		"project/scaffold.config.json": `{
+  "extends": ["../shared/go.json"],
+  "sourceRoot": "src",
+  "useGitignore": false,
+  "ignoreFolders": ["dist"],
+  "variables": {"ORG": {"default": "Initech"}, "NAME": {"type": "string"}}
+}`,
+	})
+
+	cfg, err := app.LoadConfig(filepath.Join(root, "project", "scaffold.config.json"))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if want := []string{"node_modules", ".git", "vendor", "dist"}; !reflect.DeepEqual(cfg.IgnoreFolders, want) {
+		t.Errorf("ignoreFolders = %v, want %v", cfg.IgnoreFolders, want)
+	}
+	if want := []string{"**/*.png"}; !reflect.DeepEqual(cfg.StaticFiles, want) {
+		t.Errorf("staticFiles = %v, want %v", cfg.StaticFiles, want)
+	}
+	if org := cfg.Variables["ORG"]; org.Type != "string" || !org.Required || org.Default != "Initech" {
+		t.Errorf("ORG not deep-merged: %+v", org)
+	}
+	if port := cfg.Variables["PORT"]; port.Default != "8080" {
+		t.Errorf("unquoted YAML default not kept: %+v", port)
+	}
+	if _, ok := cfg.Variables["NAME"]; !ok {
+		t.Errorf("NAME missing from merged variables")
+	}
+	if cfg.UseGitignore {
+		t.Errorf("useGitignore should be overridden to false")
+	}
+	var hooks []string
+	for _, h := range cfg.Hooks[app.HookPostGenerate] {
+		hooks = append(hooks, h.Command)
+	}
+	if want := []string{"git init", "go mod tidy"}; !reflect.DeepEqual(hooks, want) {
+		t.Errorf("postGenerate hooks = %v, want %v", hooks, want)
+	}
+	if want := filepath.Join(root, "project", "src"); cfg.SourceRoot != want {
+		t.Errorf("sourceRoot = %s, want %s", cfg.SourceRoot, want)
+	}
+	if len(cfg.Extends) != 0 {
+		t.Errorf("extends should be resolved away, got %v", cfg.Extends)
+	}
+}
+
+func TestYAMLConfigOverridesExtendedSourceRoot(t *testing.T) {
+	root := writeConfigFiles(t, map[string]string{
+		"base.json":  `{"sourceRoot": "sub", "ignoreFiles": ["*.log"]}`,
+		"child.yaml": "extends: [base.json]\nsourceRoot: other\n",
+		"keep.yaml":  "extends: [base.json]\nignoreFiles: ['*.tmp']\n",
+	})
+	cfg, err := app.LoadConfig(filepath.Join(root, "child.yaml"))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if want := filepath.Join(root, "other"); cfg.SourceRoot != want {
+		t.Errorf("sourceRoot = %s, want %s", cfg.SourceRoot, want)
+	}
+	cfg, err = app.LoadConfig(filepath.Join(root, "keep.yaml"))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if want := filepath.Join(root, "sub"); cfg.SourceRoot != want {
+		t.Errorf("inherited sourceRoot = %s, want %s", cfg.SourceRoot, want)
+	}
+}
+
+func TestConfigExtendsDetectsCycles(t *testing.T) {
+	root := writeConfigFiles(t, map[string]string{
+		"a.json": `{"extends": ["b.yaml"]}`,
+		"b.yaml": "extends: [c.json]\n",
+		"c.json": `{"extends": ["a.json"]}`,
+	})
+	_, err := app.LoadConfig(filepath.Join(root, "a.json"))
+	if err == nil || !strings.Contains(err.Error(), "config extends itself") || !strings.Contains(err.Error(), "c.json -> "+filepath.Join(root, "a.json")) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+}
+
+func TestShowResolvedConfig(t *testing.T) {
+	root := writeConfigFiles(t, map[string]string{
+		"base.json":            `{"ignoreFiles": ["*.log"]}`,
+		"scaffold.config.json": `{"extends": ["base.json"], "ignoreFiles": ["*.tmp"]}`,
+	})
+	var out bytes.Buffer
+	if err := app.ShowConfig(&out, filepath.Join(root, "scaffold.config.json"), true); err != nil {
+		t.Fatalf("show failed: %v", err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "\"ignoreFiles\": [\n    \"*.log\",\n    \"*.tmp\"\n  ]") || strings.Contains(got, "extends") {
+		t.Fatalf("unexpected resolved config:\n%s", got)
+	}
+}
+
+func TestShowResolvedYAMLConfigRoundTrips(t *testing.T) {
+	root := writeConfigFiles(t, map[string]string{
+		"base.yaml":            "sourceRoot: src\nignoreFiles: ['*.log']\nvariables:\n  PORT:\n    type: int\n    default: 8080\n",
+		"scaffold.config.yaml": "extends: [base.yaml]\nignoreFiles: ['*.tmp']\nuseGitignore: true\n",
+	})
+	var out bytes.Buffer
+	if err := app.ShowConfig(&out, filepath.Join(root, "scaffold.config.yaml"), true); err != nil {
+		t.Fatalf("show failed: %v", err)
+	}
+	got := out.String()
+	for _, key := range []string{"sourceRoot:", "ignoreFiles:", "useGitignore: true", "default: \"8080\""} {
+		if !strings.Contains(got, key) {
+			t.Fatalf("resolved config lacks %q:\n%s", key, got)
+		}
+	}
+	if strings.Contains(got, "extends") || strings.Contains(got, "sourceroot") {
+		t.Fatalf("unexpected resolved config:\n%s", got)
+	}
+
+	// The output is a config of its own that resolves to the same values
+	resolvedPath := filepath.Join(root, "resolved.yaml")
+	if err := os.WriteFile(resolvedPath, out.Bytes(), 0o644); err != nil {
+		t.Fatalf("write resolved: %v", err)
+	}
+	want, err := app.LoadConfig(filepath.Join(root, "scaffold.config.yaml"))
+	if err != nil {
+		t.Fatalf("load original: %v", err)
+	}
+	again, err := app.LoadConfig(resolvedPath)
+	if err != nil {
+		t.Fatalf("load resolved: %v", err)
+	}
+	if !reflect.DeepEqual(again, want) {
+		t.Fatalf("round trip changed the config:\n%+v\nwant:\n%+v", again, want)
+	}
+}
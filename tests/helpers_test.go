@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+// writeProject writes files below root/src and saves cfg, with SourceRoot
+// pointing at src, to root/scaffold.config.json.
+func writeProject(t *testing.T, files map[string]string, cfg *app.Config) (string, string) {
+	t.Helper()
+	return writeProjectIn(t, "src", files, cfg)
+}
+
+// writeProjectIn is writeProject with the source directory named srcDir, for
+// tests that rely on the name variations of the source directory.
+func writeProjectIn(t *testing.T, srcDir string, files map[string]string, cfg *app.Config) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, srcDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	cfg.SourceRoot = srcDir
+	configPath := filepath.Join(root, "scaffold.config.json")
+	if err := cfg.Save(configPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	return root, configPath
+}
+
+// projectOut is where runProject writes. It shares the source directory's
+// base name so that no name variations are replaced.
+func projectOut(root string) string {
+	return filepath.Join(root, "out", "src")
+}
+
+// runProject runs the config non-interactively into projectOut.
+func runProject(configPath string, set map[string]string) (*app.Result, error) {
+	return app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    projectOut(filepath.Dir(configPath)),
+		Values:     app.ValueSources{Set: set, NonInteractive: true},
+		Streams:    app.IOStreams{Out: io.Discard, ErrOut: io.Discard},
+	})
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func writeHookProject(t *testing.T, hooks map[string][]app.Hook) (string, string) {
+	t.Helper()
+	return writeProject(t, map[string]string{"main.txt": "hello {{APP}}"}, &app.Config{
+		Variables: map[string]app.Variable{
+			"APP": {Type: "string", Required: true, Default: "demo"},
+		},
+		Hooks: hooks,
+	})
+}
+
+func TestPostGenerateHookRunsInTargetDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use sh")
+	}
+	root, configPath := writeHookProject(t, map[string][]app.Hook{
+		app.HookPostGenerate: {
+			{Command: "echo {{APP}} > hook.txt", Cwd: "{{TARGET_DIR}}"},
+		},
+	})
+	outPath := filepath.Join(root, "out")
+	if err := app.RunCommand(configPath, "", outPath, false); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outPath, "hook.txt"))
+	if err != nil {
+		t.Fatalf("hook output missing: %v", err)
+	}
+	if string(data) != "demo\n" {
+		t.Fatalf("unexpected hook output: %q", string(data))
+	}
+}
+
+func TestPostGenerateHookCommandUsesTargetDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use sh")
+	}
+	root, configPath := writeHookProject(t, map[string][]app.Hook{
+		app.HookPostGenerate: {
+			{Command: "cd {{TARGET_DIR}} && pwd > target.txt && echo {{OUTPUT_DIR}} > output.txt"},
+		},
+	})
+	outPath := filepath.Join(root, "out")
+	if err := app.RunCommand(configPath, "", outPath, false); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(outPath, "target.txt")); !strings.Contains(got, "staging") {
+		t.Fatalf("TARGET_DIR = %q, want the staging directory", got)
+	}
+	if got := readFile(t, filepath.Join(outPath, "output.txt")); got != outPath+"\n" {
+		t.Fatalf("OUTPUT_DIR = %q, want the output directory %q", got, outPath)
+	}
+}
+
+func TestHookFailurePolicyContinue(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use sh")
+	}
+	root, configPath := writeHookProject(t, map[string][]app.Hook{
+		app.HookPostGenerate: {
+			{Command: "exit 3", OnFailure: "continue"},
+			{Command: "sleep 5", Timeout: "50ms", OnFailure: "continue"},
+			{Command: "touch after.txt"},
+		},
+	})
+	outPath := filepath.Join(root, "out")
+	if err := app.RunCommand(configPath, "", outPath, false); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outPath, "after.txt")); err != nil {
+		t.Fatalf("hooks after a failure with onFailure=continue should still run: %v", err)
+	}
+}
+
+func TestFailingPostGenerateHookLeavesNoOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use sh")
+	}
+	root, configPath := writeHookProject(t, map[string][]app.Hook{
+		app.HookPostGenerate: {{Command: "touch {{TARGET_DIR}}/partial.txt && exit 1"}},
+	})
+	outPath := filepath.Join(root, "out")
+	if err := app.RunCommand(configPath, "", outPath, false); err == nil {
+		t.Fatalf("expected failing hook to fail the run")
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Fatalf("output directory must not exist after a failed hook")
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatalf("read root: %v", err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), "staging") {
+			t.Fatalf("staging directory %s was left behind", e.Name())
+		}
+	}
+}
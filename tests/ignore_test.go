@@ -1,6 +1,12 @@
 package tests
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/razpinator/scaffo/internal/app"
@@ -51,3 +57,212 @@ func TestMatchInclude(t *testing.T) {
 		}
 	}
 }
+
+// The expectations below were checked against git check-ignore and git
+// status --ignored with the same files.
+func TestIgnoreMatcherFollowsGitSemantics(t *testing.T) {
+	m := app.NewIgnoreMatcher(app.ParseIgnoreRules(".scaffoldignore", strings.Join([]string{
+		"# comment",
+		"*.log",
+		"!keep.log",
+		"/build",
+		"docs/",
+		"tmp/**",
+		"!tmp/keep",
+		`\#hash`,
+		`trail\ `,
+		"**/cache/*.bin",
+		"/vendor/",
+		"!vendor/keep.go",
+	}, "\n"))...)
+	m.Add(app.ParseIgnoreRules("sub/.scaffoldignore", "*.txt\n!important.log\n")...)
+
+	cases := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"sub/app.log", false, true},
+		{"keep.log", false, false},
+		{"sub/keep.log", false, false},
+		{"build", true, true},
+		{"build/x", false, true},
+		{"src/build", true, false}, // leading slash anchors
+		{"src/build/x", false, false},
+		{"docs", true, true},
+		{"docs", false, false}, // trailing slash matches directories only
+		{"src/docs/a.md", false, true},
+		{"tmp", true, false}, // "tmp/**" matches inside tmp, not tmp itself
+		{"tmp/a", false, true},
+		{"tmp/keep", false, false},
+		{"#hash", false, true},
+		{"trail ", false, true},
+		{"trail", false, false},
+		{"a/cache/x.bin", false, true},
+		{"cache/x.bin", false, true},
+		{"vendor/keep.go", false, true}, // an excluded parent cannot be re-included
+		{"sub/a.txt", false, true},
+		{"a.txt", false, false}, // nested rules only apply below their directory
+		{"sub/important.log", false, false},
+		{"important.log", false, true},
+	}
+	for _, c := range cases {
+		if got := m.Ignored(c.path, c.isDir); got != c.want {
+			t.Errorf("Ignored(%q, dir=%v) = %v want %v", c.path, c.isDir, got, c.want)
+		}
+	}
+
+	rule, ignored := m.Match("sub/a.txt", false)
+	if !ignored || rule.Source != "sub/.scaffoldignore" || rule.Line != 1 {
+		t.Fatalf("expected sub/.scaffoldignore:1 to decide, got %v (ignored=%v)", rule, ignored)
+	}
+}
+
+func TestNestedScaffoldIgnoreDuringRun(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		".scaffoldignore":        "*.log\n/secrets/\n",
+		"app.log":                "x",
+		"keep.log":               "x",
+		"secrets/key.txt":        "x",
+		"lib/secrets/readme.md":  "x",
+		"lib/.scaffoldignore":    "!keep.log\n*.md\n",
+		"lib/keep.log":           "x",
+		"lib/other.log":          "x",
+		"lib/main.go":            "package lib",
+		"lib/nested/deep/doc.md": "x",
+	}, &app.Config{IgnoreFolders: []string{".git"}, IgnoreFiles: []string{".DS_Store"}})
+
+	if _, err := runProject(configPath, nil); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	out := projectOut(root)
+	for rel, want := range map[string]bool{
+		"app.log":                false,
+		"keep.log":               false,
+		"secrets/key.txt":        false,
+		"lib/secrets/readme.md":  false,
+		"lib/keep.log":           true,
+		"lib/other.log":          false,
+		"lib/main.go":            true,
+		"lib/nested/deep/doc.md": false,
+	} {
+		_, err := os.Stat(filepath.Join(out, filepath.FromSlash(rel)))
+		if got := err == nil; got != want {
+			t.Errorf("%s: exists = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestUseGitignoreSkipsGitExcludedPaths(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		".git/info/exclude": "local.cfg\n",
+		".gitignore":        "*.tmp\n/dist-out/\n",
+		".scaffoldignore":   "!keep.tmp\n",
+		"a.tmp":             "x",
+		"keep.tmp":          "x",
+		"local.cfg":         "x",
+		"dist-out/app.js":   "x",
+		"lib/.gitignore":    "secret.txt\n",
+		"lib/secret.txt":    "x",
+		"lib/main.go":       "package lib",
+		"secret.txt":        "x",
+	}, &app.Config{IgnoreFolders: []string{".git"}, IgnoreFiles: []string{".DS_Store"}})
+
+	if _, err := runProject(configPath, nil); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(projectOut(root), "a.tmp")); err != nil {
+		t.Fatalf(".gitignore must not apply unless enabled: %v", err)
+	}
+	if err := os.RemoveAll(projectOut(root)); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	res, err := app.Run(context.Background(), app.RunOptions{
+		ConfigPath:   configPath,
+		OutPath:      projectOut(root),
+		UseGitignore: true,
+		Verbose:      true,
+		Values:       app.ValueSources{NonInteractive: true},
+		Streams:      app.IOStreams{Out: &out, ErrOut: io.Discard},
+	})
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	for rel, want := range map[string]bool{
+		"a.tmp":           false,
+		"keep.tmp":        true,
+		"local.cfg":       false,
+		"dist-out/app.js": false,
+		"lib/secret.txt":  false,
+		"lib/main.go":     true,
+		"secret.txt":      true,
+	} {
+		_, err := os.Stat(filepath.Join(projectOut(root), filepath.FromSlash(rel)))
+		if got := err == nil; got != want {
+			t.Errorf("%s: exists = %v, want %v", rel, got, want)
+		}
+	}
+	for path, source := range map[string]string{
+		".git/":          "ignoreFolders: .git",
+		"a.tmp":          ".gitignore:1: *.tmp",
+		"dist-out/":      ".gitignore:2: /dist-out/",
+		"lib/secret.txt": "lib/.gitignore:1: secret.txt",
+		"local.cfg":      ".git/info/exclude:1: local.cfg",
+	} {
+		if res.SkippedBy[path] != source {
+			t.Errorf("SkippedBy[%q] = %q, want %q", path, res.SkippedBy[path], source)
+		}
+	}
+	if !strings.Contains(out.String(), "Skipped a.tmp (.gitignore:1: *.tmp)") {
+		t.Fatalf("verbose output does not name the ignore source:\n%s", out.String())
+	}
+}
+
+func TestIncludedPathsWinOverIgnores(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		"build/templates/app.tmpl":  "template",
+		"build/output.bin":          "x",
+		"src/cmd/main.go":           "package main",
+		"src/notes.txt":             "x",
+		"node_modules/lib/index.js": "x",
+		"docs/guide.md":             "x",
+		"README.md":                 "readme",
+	}, &app.Config{Include: []string{"build/templates/**", "src/**/*.go", "README.md"}})
+	if err := os.MkdirAll(filepath.Join(root, "src", "src", "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := runProject(configPath, nil)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	out := projectOut(root)
+	for rel, want := range map[string]bool{
+		"build/templates/app.tmpl":  true,
+		"build/output.bin":          false,
+		"src/cmd/main.go":           true,
+		"src/notes.txt":             false,
+		"src/empty":                 false,
+		"node_modules/lib/index.js": false,
+		"docs":                      false,
+		"README.md":                 true,
+	} {
+		_, err := os.Stat(filepath.Join(out, filepath.FromSlash(rel)))
+		if got := err == nil; got != want {
+			t.Errorf("%s: exists = %v, want %v", rel, got, want)
+		}
+	}
+	for path, source := range map[string]string{
+		"build/output.bin": "ignoreFolders: build",
+		"node_modules/":    "ignoreFolders: node_modules",
+		"docs/":            "not included",
+		"src/notes.txt":    "not included",
+	} {
+		if res.SkippedBy[path] != source {
+			t.Errorf("SkippedBy[%q] = %q, want %q", path, res.SkippedBy[path], source)
+		}
+	}
+}
@@ -41,8 +41,8 @@ func TestBuildTemplateAndGenerateIntegration(t *testing.T) {
 	outPath := filepath.Join(root, "generated")
 	app.GenerateCommand(cfg.TemplateRoot, outPath, false, "")
 
-	// The output path is updated to match the project name
-	actualOutPath := filepath.Join(root, "Generated App")
+	// An explicit output path is used as given
+	actualOutPath := outPath
 	generatedReadme := filepath.Join(actualOutPath, "README.txt")
 	data, err := os.ReadFile(generatedReadme)
 	if err != nil {
@@ -55,3 +55,45 @@ func TestBuildTemplateAndGenerateIntegration(t *testing.T) {
 		t.Fatalf("static file not generated: %v", err)
 	}
 }
+
+func writeOutputNameProject(t *testing.T, outputName string) string {
+	t.Helper()
+	root, _ := writeProject(t, map[string]string{"README.txt": "{{PROJECT_NAME}}"}, &app.Config{
+		OutputName: outputName,
+		Variables: map[string]app.Variable{
+			"PROJECT_NAME": {Type: "string", Required: true},
+			"PROJECT_SLUG": {Type: "string", From: "PROJECT_NAME", Transform: "slug-kebab"},
+		},
+	})
+	t.Chdir(root)
+	return root
+}
+
+func TestOutputNameUsedWhenOutOmitted(t *testing.T) {
+	root := writeOutputNameProject(t, "{{PROJECT_SLUG}}-service")
+	t.Setenv("SCAFFO_PROJECT_NAME", "My App")
+	if err := app.RunCommand("scaffold.config.json", "", "", false); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "my-app-service", "README.txt")); err != nil {
+		t.Fatalf("expected output named after outputName: %v", err)
+	}
+
+	// An explicit --out wins over outputName and the project name.
+	if err := app.RunCommand("scaffold.config.json", "", "services/billing", false); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "services", "billing", "README.txt")); err != nil {
+		t.Fatalf("expected explicit --out to be respected: %v", err)
+	}
+}
+
+func TestOutputNameRejectsUnsafeNames(t *testing.T) {
+	writeOutputNameProject(t, "")
+	for _, name := range []string{"../escape", "a/b", "CON", "what?", "trailing."} {
+		t.Setenv("SCAFFO_PROJECT_NAME", name)
+		if err := app.RunCommand("scaffold.config.json", "", "", false); err == nil {
+			t.Fatalf("expected %q to be rejected as an output directory name", name)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+	"github.com/razpinator/scaffo/pkg/scaffo"
+)
+
+func TestScaffolderRunReportsResult(t *testing.T) {
+	root, configPath := writeProjectIn(t, "Acme", map[string]string{
+		"Acme.txt":      "Welcome to Acme, {{OWNER}}",
+		"logo.png":      "\x89PNG",
+		"debug.log":     "ignored",
+		"docs/guide.md": "Acme guide",
+	}, &app.Config{
+		Variables: map[string]app.Variable{
+			"OWNER": {Type: "string", Required: true},
+		},
+	})
+
+	s := &scaffo.Scaffolder{Stdin: strings.NewReader("Jane\n")}
+	res, err := s.Run(context.Background(), scaffo.Options{
+		ConfigPath: configPath,
+		OutPath:    filepath.Join(root, "Globex"),
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(res.FilesCreated) != 2 || len(res.StaticCopied) != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if res.ReplacementsApplied != 2 {
+		t.Fatalf("expected 2 replacements, got %d", res.ReplacementsApplied)
+	}
+	if len(res.Skipped) != 1 || res.Skipped[0] != "debug.log" {
+		t.Fatalf("expected debug.log to be skipped, got %v", res.Skipped)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "Globex", "Globex.txt"))
+	if err != nil {
+		t.Fatalf("read generated file: %v", err)
+	}
+	if string(data) != "Welcome to Globex, Jane" {
+		t.Fatalf("unexpected content: %s", data)
+	}
+
+	// A second run into the same directory must fail instead of printing.
+	if _, err := s.Run(context.Background(), scaffo.Options{ConfigPath: configPath, OutPath: filepath.Join(root, "Globex")}); err == nil {
+		t.Fatalf("expected error when output path exists")
+	}
+}
+
+func TestScaffolderRunMissingRequiredVariable(t *testing.T) {
+	root := t.TempDir()
+	configPath := filepath.Join(root, "scaffold.config.json")
+	cfg := &app.Config{Variables: map[string]app.Variable{"OWNER": {Type: "string", Required: true}}}
+	if err := cfg.Save(configPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	_, err := scaffo.New().Run(context.Background(), scaffo.Options{ConfigPath: configPath, OutPath: filepath.Join(root, "out")})
+	if err == nil || !strings.Contains(err.Error(), "OWNER") {
+		t.Fatalf("expected missing OWNER error, got %v", err)
+	}
+}
+
+func TestScaffolderBuildTemplateAndGenerate(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{"app.txt": "Hello Acme"}, &app.Config{
+		Replacements: []app.Replacement{{Find: "Acme", ReplaceWith: "{{OWNER}}"}},
+		Variables:    map[string]app.Variable{"OWNER": {Type: "string", Required: true}},
+	})
+	s := scaffo.New()
+	templateRoot := filepath.Join(root, "template")
+	if _, err := s.BuildTemplate(context.Background(), scaffo.BuildOptions{ConfigPath: configPath, OutputDir: templateRoot}); err != nil {
+		t.Fatalf("BuildTemplate failed: %v", err)
+	}
+
+	outPath := filepath.Join(root, "generated")
+	res, err := s.Generate(context.Background(), scaffo.GenerateOptions{
+		TemplateRoot:   templateRoot,
+		OutPath:        outPath,
+		Set:            map[string]string{"OWNER": "Jane"},
+		NonInteractive: true,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(res.FilesCreated) != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if got := readFile(t, filepath.Join(outPath, "app.txt")); got != "Hello Jane" {
+		t.Fatalf("app.txt = %q", got)
+	}
+
+	// Generating into the same directory again must fail instead of printing.
+	if _, err := s.Generate(context.Background(), scaffo.GenerateOptions{TemplateRoot: templateRoot, OutPath: outPath, Set: map[string]string{"OWNER": "Jane"}}); err == nil {
+		t.Fatalf("expected error when output path exists")
+	}
+}
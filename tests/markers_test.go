@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func markerConfig() *app.Config {
+	return &app.Config{
+		Variables: map[string]app.Variable{
+			"ENABLE_AUTH":  {Type: "bool", Default: "true"},
+			"ENABLE_OAUTH": {Type: "bool", Default: "false"},
+			"DATABASE":     {Type: "enum", Options: []string{"none", "postgres"}, Default: "postgres"},
+		},
+	}
+}
+
+func TestMarkerBlocksAreResolved(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		"main.go": "package main\n" +
+			"// scaffo:if ENABLE_AUTH\n" +
+			"import \"auth\"\n" +
+			"\t// scaffo:if ENABLE_OAUTH\n" +
+			"import \"oauth\"\n" +
+			"\t// scaffo:else\n" +
+			"import \"password\"\n" +
+			"\t// scaffo:endif\n" +
+			"// scaffo:endif\n" +
+			"func main() {}\n",
+		"config.yaml": "# scaffo:if DATABASE == \"postgres\"\ndb: postgres\n# scaffo:endif\nport: 80\n",
+		"index.html":  "<body>\n<!-- scaffo:if !ENABLE_AUTH -->\n<p>public</p>\n<!-- scaffo:endif -->\n</body>\n",
+	}, markerConfig())
+
+	if _, err := runProject(configPath, nil); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	out := projectOut(root)
+	for name, want := range map[string]string{
+		"main.go":     "package main\nimport \"auth\"\nimport \"password\"\nfunc main() {}\n",
+		"config.yaml": "db: postgres\nport: 80\n",
+		"index.html":  "<body>\n</body>\n",
+	} {
+		if got := readFile(t, filepath.Join(out, name)); got != want {
+			t.Fatalf("%s: got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestUnbalancedMarkersReportFileAndLine(t *testing.T) {
+	cases := map[string]struct {
+		content string
+		want    string
+	}{
+		"unterminated if": {"a\n// scaffo:if ENABLE_AUTH\nb\n", "app.go:2: scaffo:if without scaffo:endif"},
+		"stray endif":     {"a\nb\n# scaffo:endif\n", "app.go:3: scaffo:endif without scaffo:if"},
+		"double else":     {"// scaffo:if ENABLE_AUTH\n// scaffo:else\n// scaffo:else\n// scaffo:endif\n", "app.go:3: second scaffo:else"},
+		"bad expression":  {"// scaffo:if ENABLE_AUTH &&\n// scaffo:endif\n", "app.go:1: expression"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, configPath := writeProject(t, map[string]string{"app.go": tc.content}, markerConfig())
+			_, err := runProject(configPath, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func writeOverwriteProject(t *testing.T) (string, string, string) {
+	t.Helper()
+	root, configPath := writeProject(t, map[string]string{"a.txt": "new a", "b.txt": "new b"}, &app.Config{})
+	outPath := filepath.Join(root, "out")
+	if err := os.MkdirAll(outPath, 0o755); err != nil {
+		t.Fatalf("mkdir out: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outPath, "a.txt"), []byte("old a"), 0o644); err != nil {
+		t.Fatalf("write existing: %v", err)
+	}
+	return root, configPath, outPath
+}
+
+func runWithOverwrite(t *testing.T, configPath, outPath, strategy, input string) (*app.Result, error) {
+	t.Helper()
+	return app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    outPath,
+		Overwrite:  strategy,
+		Streams:    app.IOStreams{In: strings.NewReader(input), Out: io.Discard, ErrOut: io.Discard},
+	})
+}
+
+func TestExistingOutputRequiresStrategy(t *testing.T) {
+	_, configPath, outPath := writeOverwriteProject(t)
+	if _, err := runWithOverwrite(t, configPath, outPath, "", ""); err == nil {
+		t.Fatalf("expected error for existing output without a strategy")
+	}
+}
+
+func TestOverwriteStrategies(t *testing.T) {
+	cases := []struct {
+		strategy string
+		input    string
+		wantA    string
+		backup   bool
+		check    func(res *app.Result) bool
+	}{
+		{app.OverwriteForce, "", "new a", false, func(r *app.Result) bool { return len(r.Overwritten) == 1 }},
+		{app.OverwriteSkip, "", "old a", false, func(r *app.Result) bool { return len(r.SkippedExisting) == 1 }},
+		{app.OverwriteBackup, "", "new a", true, func(r *app.Result) bool { return len(r.BackedUp) == 1 }},
+		{app.OverwriteInteractive, "maybe\nn\n", "old a", false, func(r *app.Result) bool { return len(r.SkippedExisting) == 1 }},
+		{app.OverwriteInteractive, "b\n", "new a", true, func(r *app.Result) bool { return len(r.BackedUp) == 1 }},
+	}
+	for _, c := range cases {
+		t.Run(c.strategy, func(t *testing.T) {
+			_, configPath, outPath := writeOverwriteProject(t)
+			res, err := runWithOverwrite(t, configPath, outPath, c.strategy, c.input)
+			if err != nil {
+				t.Fatalf("run failed: %v", err)
+			}
+			if got := readFile(t, filepath.Join(outPath, "a.txt")); got != c.wantA {
+				t.Fatalf("a.txt = %q want %q", got, c.wantA)
+			}
+			if got := readFile(t, filepath.Join(outPath, "b.txt")); got != "new b" {
+				t.Fatalf("b.txt = %q, new files must always be written", got)
+			}
+			_, statErr := os.Stat(filepath.Join(outPath, "a.txt.bak"))
+			if c.backup != (statErr == nil) {
+				t.Fatalf("backup exists = %v, want %v", statErr == nil, c.backup)
+			}
+			if c.backup && readFile(t, filepath.Join(outPath, "a.txt.bak")) != "old a" {
+				t.Fatalf("backup does not hold the previous content")
+			}
+			if !c.check(res) {
+				t.Fatalf("unexpected conflict summary: %+v", res)
+			}
+		})
+	}
+}
+
+func TestBackupTwiceKeepsEarlierBackups(t *testing.T) {
+	_, configPath, outPath := writeOverwriteProject(t)
+	for i := 0; i < 2; i++ {
+		res, err := runWithOverwrite(t, configPath, outPath, app.OverwriteBackup, "")
+		if err != nil {
+			t.Fatalf("run %d failed: %v", i+1, err)
+		}
+		if want := i + 1; len(res.BackedUp) != want {
+			t.Fatalf("run %d backed up %v", i+1, res.BackedUp)
+		}
+	}
+	for name, want := range map[string]string{
+		"a.txt":       "new a",
+		"a.txt.bak":   "old a",
+		"a.txt.bak.1": "new a",
+		"b.txt.bak":   "new b",
+	} {
+		if got := readFile(t, filepath.Join(outPath, name)); got != want {
+			t.Fatalf("%s = %q want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func profilesConfig() *app.Config {
+	return &app.Config{
+		IgnoreFolders: []string{".git", "web"},
+		IgnoreFiles:   []string{"*.log"},
+		Variables: map[string]app.Variable{
+			"PROJECT_NAME": {Type: "string", Required: true},
+			"PORT":         {Type: "int", Default: "3000"},
+		},
+		Replacements: []app.Replacement{{Find: "acme", ReplaceWith: "{{PROJECT_NAME}}"}},
+		Hooks:        map[string][]app.Hook{app.HookPostGenerate: {{Command: "touch base.txt"}}},
+		Profiles: map[string]app.Profile{
+			"api": {
+				IgnoreFolders: []string{"cli", "!web"},
+				IgnoreFiles:   []string{"!*.log"},
+				Variables:     map[string]app.Variable{"PORT": {Type: "int", Default: "8080"}},
+				Replacements:  []app.Replacement{{Find: "acme", ReplaceWith: "{{PROJECT_NAME}}-api"}},
+				Hooks:         map[string][]app.Hook{app.HookPostGenerate: {{Command: "touch api.txt"}}},
+			},
+			"cli": {IgnoreFolders: []string{"web", "api"}},
+		},
+	}
+}
+
+func runProfile(configPath, profile string) (*app.Result, error) {
+	return app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    projectOut(filepath.Dir(configPath)),
+		Profile:    profile,
+		Values:     app.ValueSources{Set: map[string]string{"PROJECT_NAME": "shop"}, NonInteractive: true},
+		Streams:    app.IOStreams{Out: io.Discard, ErrOut: io.Discard},
+	})
+}
+
+func TestProfileOverridesAndExtendsConfig(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		"app.txt":      "acme on {{PORT}}",
+		"web/index.js": "x",
+		"cli/main.go":  "x",
+		"debug.log":    "x",
+	}, profilesConfig())
+
+	if _, err := runProfile(configPath, "api"); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	out := projectOut(root)
+	if got := readFile(t, filepath.Join(out, "app.txt")); got != "shop-api on 8080" {
+		t.Fatalf("profile variables and replacements not applied: %q", got)
+	}
+	for rel, want := range map[string]bool{
+		"web/index.js": true,
+		"cli/main.go":  false,
+		"debug.log":    true,
+		"base.txt":     true,
+		"api.txt":      true,
+	} {
+		_, err := os.Stat(filepath.Join(out, filepath.FromSlash(rel)))
+		if got := err == nil; got != want {
+			t.Errorf("%s: exists = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestNoProfileUsesBaseConfig(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{"app.txt": "acme on {{PORT}}"}, profilesConfig())
+	if _, err := runProfile(configPath, ""); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if got := readFile(t, filepath.Join(projectOut(root), "app.txt")); got != "shop on 3000" {
+		t.Fatalf("unexpected output without a profile: %q", got)
+	}
+}
+
+func TestUnknownProfileListsAvailableProfiles(t *testing.T) {
+	_, configPath := writeProject(t, map[string]string{"app.txt": "x"}, profilesConfig())
+	_, err := runProfile(configPath, "spa")
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "spa" (available: api, cli)`) {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
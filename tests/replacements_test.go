@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func TestRegexReplacementWithCaptureGroups(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		"Main.java": "package com.acme.billing;\nimport com.acme.core.Money;\n",
+	}, &app.Config{
+		Variables: map[string]app.Variable{"NAMESPACE": {Type: "string", Required: true}},
+		Replacements: []app.Replacement{
+			{Find: `import (?P<pkg>[\w.]+)\.Money`, ReplaceWith: "import ${pkg}.Amount", Regex: true},
+			{Find: `com\.acme\.(\w+)`, ReplaceWith: "{{NAMESPACE}}.$1", Regex: true},
+		},
+	})
+
+	if _, err := runProject(configPath, map[string]string{"NAMESPACE": "org.example"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	got := readFile(t, filepath.Join(projectOut(root), "Main.java"))
+	want := "package org.example.billing;\nimport org.example.core.Amount;\n"
+	if got != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegexReplacementErrorsNameTheReplacement(t *testing.T) {
+	cases := []struct {
+		repl app.Replacement
+		want string
+	}{
+		{app.Replacement{Find: `acme(`, ReplaceWith: "x", Regex: true}, "replacements[1]: invalid regex"},
+		{app.Replacement{Find: `acme(\w+)`, ReplaceWith: "$2", Regex: true}, "replacements[1]: replaceWith refers to unknown capture group $2"},
+		{app.Replacement{Find: `acme(\w+)`, ReplaceWith: "$1x", Regex: true}, "unknown capture group $1x"},
+	}
+	for _, tc := range cases {
+		_, configPath := writeProject(t, map[string]string{"app.txt": "acme"}, &app.Config{
+			Replacements: []app.Replacement{{Find: "acme(", ReplaceWith: "literal"}, tc.repl},
+		})
+		if _, err := app.LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q -> %q: expected error containing %q, got %v", tc.repl.Find, tc.repl.ReplaceWith, tc.want, err)
+		}
+	}
+}
+
+func TestScopedReplacementsAndRenameRules(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		"src/Acme/Service.cs":      "namespace Acme;",
+		"Acme.csproj":              "<Name>Acme</Name>",
+		"vendor/acme-lib/Acme.txt": "Copyright Acme",
+		"docs/Acme.md":             "Acme docs",
+	}, &app.Config{
+		IgnoreFolders: []string{".git"},
+		Variables:     map[string]app.Variable{"ORG": {Type: "string", Required: true}},
+		Replacements: []app.Replacement{
+			{Find: "Acme", ReplaceWith: "{{ORG}}", Include: []string{"src/**", "*.csproj"}},
+		},
+		RenameRules: []app.RenameRule{
+			{From: "Acme", To: "{{ORG}}", Exclude: []string{"vendor/**", "*.md"}},
+		},
+	})
+
+	if _, err := runProject(configPath, map[string]string{"ORG": "Initech"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	out := projectOut(root)
+	want := map[string]string{
+		"src/Initech/Service.cs":   "namespace Initech;",
+		"Initech.csproj":           "<Name>Initech</Name>",
+		"vendor/acme-lib/Acme.txt": "Copyright Acme",
+		"docs/Acme.md":             "Acme docs",
+	}
+	for rel, content := range want {
+		if got := readFile(t, filepath.Join(out, filepath.FromSlash(rel))); got != content {
+			t.Errorf("%s: got %q, want %q", rel, got, content)
+		}
+	}
+}
+
+func TestRegexReplacementsKeepConfigOrder(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{"app.txt": "foo bar"}, &app.Config{
+		Replacements: []app.Replacement{
+			{Find: "fo", ReplaceWith: "zz"},
+			{Find: "foo", ReplaceWith: "bar"},
+			{Find: `b(a)r`, ReplaceWith: "X$1", Regex: true},
+		},
+	})
+
+	if _, err := runProject(configPath, nil); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	// "foo" is longer than "fo" and goes first; the regex runs last, as listed
+	if got := readFile(t, filepath.Join(projectOut(root), "app.txt")); got != "Xa Xa" {
+		t.Fatalf("app.txt = %q, want %q", got, "Xa Xa")
+	}
+}
@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func filterConfig() *app.Config {
+	return &app.Config{Variables: map[string]app.Variable{
+		"PROJECT_NAME": {Type: "string", Required: true},
+	}}
+}
+
+func TestTokenFiltersInContentAndPaths(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		"{{PROJECT_NAME|kebab}}/{{PROJECT_NAME|pascal}}.cs": "namespace {{PROJECT_NAME|pascal}};\n" +
+			"const {{ PROJECT_NAME | snake | upper }} = \"{{PROJECT_NAME}}\";\n" +
+			"// {{ .Values.image }} and {{UNKNOWN|kebab}} stay as they are\n",
+	}, filterConfig())
+
+	if _, err := runProject(configPath, map[string]string{"PROJECT_NAME": "order service"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	got := readFile(t, filepath.Join(projectOut(root), "order-service", "OrderService.cs"))
+	want := "namespace OrderService;\n" +
+		"const ORDER_SERVICE = \"order service\";\n" +
+		"// {{ .Values.image }} and {{UNKNOWN|kebab}} stay as they are\n"
+	if got != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTokenFiltersRespectCustomDelimiters(t *testing.T) {
+	cfg := filterConfig()
+	cfg.Token = map[string]string{"start": "<%", "end": "%>"}
+	root, configPath := writeProject(t, map[string]string{
+		"app.txt": "<%PROJECT_NAME|camel%> {{PROJECT_NAME|kebab}} <%%><%PROJECT_NAME|flat%>",
+	}, cfg)
+
+	if _, err := runProject(configPath, map[string]string{"PROJECT_NAME": "Order Service"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	got := readFile(t, filepath.Join(projectOut(root), "app.txt"))
+	if got != "orderService {{PROJECT_NAME|kebab}} <%%>orderservice" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+func TestUnknownTokenFilterReportsFileAndLine(t *testing.T) {
+	_, configPath := writeProject(t, map[string]string{
+		"docs/readme.md": "# Title\n\n{{PROJECT_NAME|shout}}\n",
+	}, filterConfig())
+
+	_, err := runProject(configPath, map[string]string{"PROJECT_NAME": "x"})
+	if err == nil || !strings.Contains(err.Error(), `docs/readme.md:3: unknown filter "shout"`) {
+		t.Fatalf("expected unknown filter error at docs/readme.md:3, got %v", err)
+	}
+}
+
+func TestUnresolvedTokensReportedWithFileAndLine(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		"{{MISSING_DIR}}/app.txt": "{{PROJECT_NAME}}\n\nhello {{UNKNOWN}}\n",
+		"chart.yaml": "image: {{{{ .Values.image }}\n" +
+			"{{raw}}\n{{ .Values.tag }} {{PROJECT_NAME}}\n{{endraw}}\n" +
+			"name: {{PROJECT_NAME}}\n",
+	}, filterConfig())
+
+	res, err := runProject(configPath, map[string]string{"PROJECT_NAME": "api"})
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	var got []string
+	for _, tok := range res.Unresolved {
+		got = append(got, tok.String())
+	}
+	want := []string{"{{MISSING_DIR}}: {{MISSING_DIR}}", "{{MISSING_DIR}}/app.txt:3: {{UNKNOWN}}"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected unresolved tokens:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	chart := readFile(t, filepath.Join(projectOut(root), "chart.yaml"))
+	wantChart := "image: {{ .Values.image }}\n\n{{ .Values.tag }} {{PROJECT_NAME}}\n\nname: api\n"
+	if chart != wantChart {
+		t.Fatalf("escapes not applied:\n%q\nwant:\n%q", chart, wantChart)
+	}
+}
+
+func TestStrictFailsOnUnresolvedTokens(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		"app.txt": "{{PROJECT_NAME}} {{UNKNOWN}}",
+	}, filterConfig())
+
+	_, err := app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    projectOut(root),
+		Strict:     true,
+		Values:     app.ValueSources{Set: map[string]string{"PROJECT_NAME": "api"}, NonInteractive: true},
+		Streams:    app.IOStreams{Out: io.Discard, ErrOut: io.Discard},
+	})
+	if err == nil || !strings.Contains(err.Error(), "1 unresolved token") {
+		t.Fatalf("expected strict mode to fail, got %v", err)
+	}
+	if _, statErr := os.Stat(projectOut(root)); !os.IsNotExist(statErr) {
+		t.Fatalf("strict failure must not leave output behind")
+	}
+}
+
+func TestStrictRejectedWithGoTemplateEngine(t *testing.T) {
+	cfg := filterConfig()
+	cfg.Engine = app.EngineGoTemplate
+	root, configPath := writeProject(t, map[string]string{"app.txt": "{{.PROJECT_NAME}}"}, cfg)
+
+	_, err := app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    projectOut(root),
+		Strict:     true,
+		Values:     app.ValueSources{Set: map[string]string{"PROJECT_NAME": "api"}, NonInteractive: true},
+		Streams:    app.IOStreams{Out: io.Discard, ErrOut: io.Discard},
+	})
+	if err == nil || !strings.Contains(err.Error(), "--strict") {
+		t.Fatalf("expected --strict to be rejected with the go-template engine, got %v", err)
+	}
+	if _, statErr := os.Stat(projectOut(root)); !os.IsNotExist(statErr) {
+		t.Fatalf("a rejected run must not leave output behind")
+	}
+}
+
+func TestRawBlockWithoutEndRaw(t *testing.T) {
+	_, configPath := writeProject(t, map[string]string{
+		"app.txt": "a\n{{raw}}\n{{PROJECT_NAME}}\n",
+	}, filterConfig())
+	if _, err := runProject(configPath, map[string]string{"PROJECT_NAME": "api"}); err == nil || !strings.Contains(err.Error(), "app.txt:2: {{raw}} without {{endraw}}") {
+		t.Fatalf("expected raw block error, got %v", err)
+	}
+}
+
+func TestTokenOverridesChangeDelimitersPerGlob(t *testing.T) {
+	cfg := filterConfig()
+	cfg.Replacements = []app.Replacement{{Find: "demo", ReplaceWith: "{{PROJECT_NAME}}"}}
+	cfg.TokenOverrides = []app.TokenOverride{{Glob: "charts/**", Start: "[[", End: "]]"}}
+	root, configPath := writeProject(t, map[string]string{
+		"charts/[[PROJECT_NAME]]/values.yaml": "name: demo\nimage: {{ .Values.image }}\ntag: [[PROJECT_NAME|upper]]\n",
+		"app.txt":                             "{{PROJECT_NAME}} demo",
+	}, cfg)
+
+	if _, err := runProject(configPath, map[string]string{"PROJECT_NAME": "api"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	got := readFile(t, filepath.Join(projectOut(root), "charts", "api", "values.yaml"))
+	if want := "name: api\nimage: {{ .Values.image }}\ntag: API\n"; got != want {
+		t.Fatalf("unexpected chart:\n%q\nwant:\n%q", got, want)
+	}
+	if got := readFile(t, filepath.Join(projectOut(root), "app.txt")); got != "api api" {
+		t.Fatalf("default delimiters not applied outside the override: %q", got)
+	}
+}
+
+func TestTokenOverridesRequireAllFields(t *testing.T) {
+	cfg := filterConfig()
+	cfg.TokenOverrides = []app.TokenOverride{{Glob: "charts/**", Start: "[["}}
+	_, configPath := writeProject(t, map[string]string{"app.txt": "x"}, cfg)
+	if _, err := app.LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "tokenOverrides[0]") {
+		t.Fatalf("expected tokenOverrides error, got %v", err)
+	}
+}
+
+func TestInitWarnsAboutExistingDelimiters(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "charts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "charts", "values.yaml"), []byte("a: 1\nimage: {{ .Values.image }}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	initErr := app.InitCommand(filepath.Join(t.TempDir(), "scaffold.config.json"), root)
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+	if initErr != nil {
+		t.Fatalf("init failed: %v", initErr)
+	}
+	if !strings.Contains(string(out), "1 file(s) already contain the token delimiters") || !strings.Contains(string(out), "charts/values.yaml:2") {
+		t.Fatalf("expected delimiter warning, got:\n%s", out)
+	}
+}
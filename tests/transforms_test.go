@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/razpinator/scaffo/internal/app"
+)
+
+func TestTransformLibrary(t *testing.T) {
+	cases := []struct {
+		input     string
+		transform app.Transform
+		want      string
+	}{
+		{"order item", "pascal", "OrderItem"},
+		{"order item", "camel", "orderItem"},
+		{"OrderItem", "snake", "order_item"},
+		{"OrderItem", "kebab", "order-item"},
+		{"orderItem", "screaming", "ORDER_ITEM"},
+		{"OrderItem", "dot", "order.item"},
+		{"OrderItem", "path", "order/item"},
+		{"order_item", "train", "Order-Item"},
+		{"Order Item", "flat", "orderitem"},
+		{"Café Über", "slug-kebab", "cafe-uber"},
+		{"Crème Brûlée", "ascii", "Creme Brulee"},
+		{"Straße", "ascii|upper", "STRASSE"},
+		{"OrderItem", "plural", "OrderItems"},
+		{"category", "plural", "categories"},
+		{"box", "plural", "boxes"},
+		{"Person", "plural", "People"},
+		{"HTTPServer", "plural", "HTTPServers"},
+		{"user_addresses", "singular", "user_address"},
+		{"CATEGORIES", "singular", "CATEGORY"},
+		{"statuses", "singular", "status"},
+		{"databases", "singular", "database"},
+		{"sheep", "singular", "sheep"},
+		{"order items", "singular|pascal", "OrderItem"},
+	}
+	// One project derives a variable per case, so a single run covers them all
+	vars := map[string]app.Variable{}
+	set := map[string]string{}
+	var lines []string
+	for i, tc := range cases {
+		in, out := fmt.Sprintf("IN_%d", i), fmt.Sprintf("OUT_%d", i)
+		vars[in] = app.Variable{Type: "string", Required: true}
+		vars[out] = app.Variable{Type: "string", From: in, Transform: tc.transform}
+		set[in] = tc.input
+		lines = append(lines, "{{"+out+"}}")
+	}
+	root, configPath := writeProject(t, map[string]string{"out.txt": strings.Join(lines, "\n")}, &app.Config{Variables: vars})
+	if _, err := runProject(configPath, set); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	got := strings.Split(readFile(t, filepath.Join(projectOut(root), "out.txt")), "\n")
+	if len(got) != len(cases) {
+		t.Fatalf("got %d lines for %d cases", len(got), len(cases))
+	}
+	for i, tc := range cases {
+		if got[i] != tc.want {
+			t.Errorf("%s(%q) = %q, want %q", tc.transform, tc.input, got[i], tc.want)
+		}
+	}
+}
@@ -1,7 +1,16 @@
 package tests
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/razpinator/scaffo/internal/app"
 )
@@ -35,3 +44,218 @@ func TestApplyVariableReplacementsEmptyOverrides(t *testing.T) {
 		t.Fatalf("expected defaults used, got %s", res)
 	}
 }
+
+func writeTypedVariableProject(t *testing.T, vars map[string]app.Variable) (string, string) {
+	t.Helper()
+	return writeProject(t, map[string]string{
+		"app.txt": "port={{PORT}} docker={{DOCKER}} db={{DB}} tags={{TAGS}}",
+	}, &app.Config{Variables: vars})
+}
+
+func typedVariables() map[string]app.Variable {
+	lo, hi := 1024, 65535
+	return map[string]app.Variable{
+		"PORT":   {Type: "int", Required: true, Min: &lo, Max: &hi},
+		"DOCKER": {Type: "bool", Required: true},
+		"DB":     {Type: "enum", Required: true, Options: []string{"postgres", "mysql"}},
+		"TAGS":   {Type: "list", Separator: ","},
+	}
+}
+
+func TestTypedVariablesRepromptUntilValid(t *testing.T) {
+	root, configPath := writeTypedVariableProject(t, typedVariables())
+	var out bytes.Buffer
+	// Prompts are asked in alphabetical order: DB, DOCKER, PORT, TAGS.
+	input := strings.Join([]string{
+		"ssh", "Postgres",
+		"maybe", "yes",
+		"80", "8080",
+		" api , web ,",
+	}, "\n") + "\n"
+	res, err := app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    filepath.Join(root, "out"),
+		Streams:    app.IOStreams{In: strings.NewReader(input), Out: &out, ErrOut: &out},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v\n%s", err, out.String())
+	}
+	data, err := os.ReadFile(filepath.Join(res.OutPath, "app.txt"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	want := "port=8080 docker=true db=postgres tags=api,web"
+	if string(data) != want {
+		t.Fatalf("got %q want %q", data, want)
+	}
+	for _, msg := range []string{"must be one of: postgres, mysql", "must be yes/no", "must be at least 1024"} {
+		if !strings.Contains(out.String(), msg) {
+			t.Fatalf("expected re-prompt message %q in output:\n%s", msg, out.String())
+		}
+	}
+}
+
+func TestTypedVariableInvalidEnvFails(t *testing.T) {
+	root, configPath := writeTypedVariableProject(t, typedVariables())
+	t.Setenv("SCAFFO_PORT", "http")
+	_, err := app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    filepath.Join(root, "out"),
+		Streams:    app.IOStreams{In: strings.NewReader("postgres\nno\n"), Out: io.Discard, ErrOut: io.Discard},
+	})
+	if err == nil || !strings.Contains(err.Error(), "SCAFFO_PORT") {
+		t.Fatalf("expected SCAFFO_PORT validation error, got %v", err)
+	}
+}
+
+func TestValueSourcePrecedence(t *testing.T) {
+	root, configPath := writeTypedVariableProject(t, typedVariables())
+	valuesPath := filepath.Join(root, "values.yaml")
+	values := "PORT: 9000\nDOCKER: false\nDB: mysql\nTAGS: [api, web]\n"
+	if err := os.WriteFile(valuesPath, []byte(values), 0o644); err != nil {
+		t.Fatalf("write values: %v", err)
+	}
+	t.Setenv("SCAFFO_PORT", "7000")
+	t.Setenv("SCAFFO_DB", "postgres")
+
+	res, err := app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    filepath.Join(root, "out"),
+		Values: app.ValueSources{
+			Set:            map[string]string{"PORT": "8000"},
+			ValuesFile:     valuesPath,
+			NonInteractive: true,
+		},
+		Streams: app.IOStreams{In: strings.NewReader(""), Out: io.Discard, ErrOut: io.Discard},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(res.OutPath, "app.txt"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	want := "port=8000 docker=false db=mysql tags=api,web"
+	if string(data) != want {
+		t.Fatalf("got %q want %q", data, want)
+	}
+}
+
+func TestNonInteractiveListsMissingVariables(t *testing.T) {
+	root, configPath := writeTypedVariableProject(t, typedVariables())
+	_, err := app.Run(context.Background(), app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    filepath.Join(root, "out"),
+		Values:     app.ValueSources{Set: map[string]string{"DB": "mysql"}, NonInteractive: true},
+		Streams:    app.IOStreams{In: strings.NewReader("unused\n"), Out: io.Discard, ErrOut: io.Discard},
+	})
+	if err == nil || !strings.Contains(err.Error(), "DOCKER, PORT") {
+		t.Fatalf("expected DOCKER and PORT to be reported missing, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(root, "out")); !os.IsNotExist(statErr) {
+		t.Fatalf("nothing should be written when variables are missing")
+	}
+}
+
+func TestDerivedVariablesResolveInDependencyOrder(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{
+		"app.txt": "{{NAMESPACE}} {{PROJECT_SLUG}} {{CONST}}",
+	}, &app.Config{Variables: map[string]app.Variable{
+		"PROJECT_NAME": {Type: "string", Required: true},
+		"PROJECT_SLUG": {Type: "string", From: "PROJECT_NAME", Transform: "slug-kebab"},
+		"NAMESPACE":    {Type: "string", From: "PROJECT_SLUG", Transform: "upper", Default: "fallback"},
+		"CONST":        {Type: "string", From: "PROJECT_NAME", Transform: "slug-snake|upper"},
+	}})
+	if _, err := runProject(configPath, map[string]string{"PROJECT_NAME": "Billing Service"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	got := readFile(t, filepath.Join(projectOut(root), "app.txt"))
+	if got != "BILLING-SERVICE billing-service BILLING_SERVICE" {
+		t.Fatalf("unexpected derived values: %q", got)
+	}
+}
+
+func TestDerivedVariableCycleReported(t *testing.T) {
+	_, configPath := writeProject(t, map[string]string{"app.txt": "x"}, &app.Config{Variables: map[string]app.Variable{
+		"A": {Type: "string", From: "C"},
+		"B": {Type: "string", From: "A"},
+		"C": {Type: "string", From: "B"},
+	}})
+	_, err := runProject(configPath, nil)
+	if err == nil || !strings.Contains(err.Error(), "A -> C -> B -> A") {
+		t.Fatalf("expected cycle A -> C -> B -> A, got %v", err)
+	}
+}
+
+func TestTransformPipelineFromConfigList(t *testing.T) {
+	for name, config := range map[string]string{
+		"scaffold.config.json": `{"variables": {"NAME": {"type": "string"}, "CONST": {"from": "NAME", "transform": ["slug-snake", "upper"]}}}`,
+		"scaffold.config.yaml": "variables:\n  NAME: {type: string}\n  CONST:\n    from: NAME\n    transform: [slug-snake, upper]\n",
+	} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		cfg, err := app.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("%s: load failed: %v", name, err)
+		}
+		if got := cfg.Variables["CONST"].Transform; got != "slug-snake|upper" {
+			t.Fatalf("%s: expected pipeline slug-snake|upper, got %q", name, got)
+		}
+	}
+}
+
+func TestUnknownTransformRejected(t *testing.T) {
+	_, configPath := writeProject(t, map[string]string{"app.txt": "x"}, &app.Config{Variables: map[string]app.Variable{
+		"NAME": {Type: "string", Default: "x"},
+		"SLUG": {Type: "string", From: "NAME", Transform: "slug-kebab|shout"},
+	}})
+	if _, err := runProject(configPath, nil); err == nil || !strings.Contains(err.Error(), `unknown transform "shout"`) {
+		t.Fatalf("expected unknown transform error, got %v", err)
+	}
+}
+
+// cancelOnWrite cancels a run as soon as it prints its first prompt.
+type cancelOnWrite struct{ cancel context.CancelFunc }
+
+func (w cancelOnWrite) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte("OWNER")) {
+		w.cancel()
+	}
+	return len(p), nil
+}
+
+func TestCancelledPromptKeepsNextLine(t *testing.T) {
+	root, configPath := writeProject(t, map[string]string{"app.txt": "{{OWNER}}"}, &app.Config{
+		Variables: map[string]app.Variable{"OWNER": {Type: "string", Required: true}},
+	})
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	in := bufio.NewReader(pr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := app.Run(ctx, app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    projectOut(root),
+		Streams:    app.IOStreams{In: in, Out: cancelOnWrite{cancel}, ErrOut: io.Discard},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancelled prompt to fail the run, got %v", err)
+	}
+
+	// The line typed after the cancelled prompt goes to the next run
+	go pw.Write([]byte("Jane\n"))
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := app.Run(ctx, app.RunOptions{
+		ConfigPath: configPath,
+		OutPath:    projectOut(root),
+		Streams:    app.IOStreams{In: in, Out: io.Discard, ErrOut: io.Discard},
+	}); err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+	if got := readFile(t, filepath.Join(projectOut(root), "app.txt")); got != "Jane" {
+		t.Fatalf("app.txt = %q, want %q", got, "Jane")
+	}
+}